@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+// Commands whose array reply is a list of keys, printed sorted with a count.
+var searchCommands = []string{"SEARCHVALUE", "SEARCHPREFIX", "SEARCHSUFFIX", "SEARCHCONTAINS"}
+
+// formatReply prints reply the way redis-cli does: quoted strings, "(integer)"
+// and "(error)" annotations, numbered array elements. Raw mode prints bare
+// values, one array element per line.
+func formatReply(reply resp.Value, command string, raw bool) string {
+	if raw {
+		return formatRawReply(reply)
+	}
+
+	if reply.Type == resp.ARRAY_TYPE && slices.Contains(searchCommands, command) {
+		return formatSearchReply(reply)
+	}
+	return formatValue(reply, "")
+}
+
+func formatRawReply(reply resp.Value) string {
+	switch reply.Type {
+	case resp.INTEGER_TYPE:
+		return strconv.FormatInt(reply.Int, 10)
+	case resp.BOOLEAN_TYPE:
+		return lo.Ternary(reply.Int == 1, "true", "false")
+	case resp.NULL_TYPE:
+		return ""
+	case resp.ARRAY_TYPE, resp.MAP_TYPE, resp.PUSH_TYPE:
+		return strings.Join(lo.Map(reply.Array, func(element resp.Value, _ int) string {
+			return formatRawReply(element)
+		}), "\n")
+	default:
+		return reply.Str
+	}
+}
+
+func formatSearchReply(reply resp.Value) string {
+	if len(reply.Array) == 0 {
+		return "(no keys found)"
+	}
+
+	keys := lo.Map(reply.Array, func(element resp.Value, _ int) string { return element.Str })
+	slices.Sort(keys)
+
+	width := len(strconv.Itoa(len(keys)))
+	lines := lo.Map(keys, func(key string, index int) string {
+		return fmt.Sprintf("%*d) %s", width, index+1, key)
+	})
+
+	return fmt.Sprintf("%s\n(%d %s)", strings.Join(lines, "\n"), len(keys), lo.Ternary(len(keys) == 1, "key", "keys"))
+}
+
+// formatValue formats nested arrays with their elements indented under the
+// number of their parent, indentation being the width of that number.
+func formatValue(value resp.Value, indentation string) string {
+	switch value.Type {
+	case resp.SIMPLE_STRING_TYPE:
+		return value.Str
+	case resp.ERROR_TYPE:
+		return "(error) " + value.Str
+	case resp.INTEGER_TYPE:
+		return fmt.Sprintf("(integer) %d", value.Int)
+	case resp.BOOLEAN_TYPE:
+		return lo.Ternary(value.Int == 1, "(true)", "(false)")
+	case resp.NULL_TYPE:
+		return "(nil)"
+	case resp.BULK_STRING_TYPE:
+		return strconv.Quote(value.Str)
+	case resp.ARRAY_TYPE, resp.MAP_TYPE, resp.PUSH_TYPE:
+		if len(value.Array) == 0 {
+			return "(empty array)"
+		}
+
+		width := len(strconv.Itoa(len(value.Array)))
+		lines := lo.Map(value.Array, func(element resp.Value, index int) string {
+			prefix := fmt.Sprintf("%*d) ", width, index+1)
+			line := prefix + formatValue(element, indentation+strings.Repeat(" ", len(prefix)))
+			return lo.Ternary(index == 0, line, indentation+line)
+		})
+		return strings.Join(lines, "\n")
+	default:
+		return value.Str
+	}
+}
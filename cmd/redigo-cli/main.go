@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"redigo/pkg/client"
+	"redigo/pkg/resp"
+)
+
+type cli struct {
+	client  *client.Client
+	session *client.Conn // Connection running every command but the streaming ones, so that SELECT, AUTH and MULTI carry over
+	raw     bool         // Print replies without type annotations nor quotes
+	prompt  string       // Prompt of the interactive shell, also the server address
+
+	commandNames []string // Uppercase names used for completion, fetched on first use
+}
+
+func main() {
+	host := flag.String("host", "127.0.0.1", "Server hostname")
+	port := flag.String("p", "6379", "Server port")
+	socket := flag.String("s", "", "Server Unix socket path, overrides host and port")
+	username := flag.String("user", "", "ACL username")
+	password := flag.String("a", "", "Password used to AUTH")
+	database := flag.Int("n", 0, "Database number")
+	raw := flag.Bool("raw", false, "Print raw replies, without type annotations nor quotes")
+	file := flag.String("f", "", "Run the commands of a file, one per line, then exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: redigo-cli [options] [command [argument ...]]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	options := client.Options{Username: *username, Password: *password, Database: *database, PoolSize: 1}
+	prompt := ""
+	if *socket != "" {
+		options.Network, options.Address = "unix", *socket
+		prompt = fmt.Sprintf("redigo %s> ", *socket)
+	} else {
+		options.Network, options.Address = "tcp", net.JoinHostPort(*host, *port)
+		prompt = options.Address + "> "
+	}
+
+	shell := newCli(client.New(options), *raw, prompt)
+	defer shell.close()
+
+	switch {
+	case flag.NArg() > 0:
+		os.Exit(exitCode(shell.run(flag.Args())))
+	case *file != "":
+		commandsFile, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open '%s': %v\n", *file, err)
+			os.Exit(1)
+		}
+		defer commandsFile.Close()
+		os.Exit(exitCode(shell.runLines(commandsFile)))
+	case !isTerminal(os.Stdin):
+		os.Exit(exitCode(shell.runLines(os.Stdin)))
+	default:
+		shell.repl()
+	}
+}
+
+func newCli(redigoClient *client.Client, raw bool, prompt string) *cli {
+	return &cli{client: redigoClient, session: redigoClient.Conn(), raw: raw, prompt: prompt}
+}
+
+func (shell *cli) close() {
+	shell.session.Close()
+	shell.client.Close()
+}
+
+func exitCode(success bool) int {
+	if success {
+		return 0
+	}
+	return 1
+}
+
+// Commands switching the connection to push mode, their replies are streamed.
+var streamingCommands = []string{"SUBSCRIBE", "PSUBSCRIBE", "MONITOR"}
+
+// run sends one command and prints its reply, it reports whether the server
+// answered without an error.
+func (shell *cli) run(arguments []string) bool {
+	if slices.Contains(streamingCommands, strings.ToUpper(arguments[0])) {
+		return shell.stream(arguments)
+	}
+
+	reply, err := shell.session.Do(context.Background(), arguments...)
+	if err != nil && !reply.IsError() {
+		fmt.Fprintf(os.Stderr, "Could not connect to redigo at %s: %v\n", strings.TrimSuffix(shell.prompt, "> "), err)
+		return false
+	}
+
+	fmt.Println(formatReply(reply, strings.ToUpper(arguments[0]), shell.raw))
+	return !reply.IsError()
+}
+
+// stream prints the replies of a streaming command as they arrive, until the
+// server closes the connection or Ctrl-C is pressed.
+func (shell *cli) stream(arguments []string) bool {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	stream, err := shell.client.Stream(ctx, arguments...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Could not connect to redigo at %s: %v\n", strings.TrimSuffix(shell.prompt, "> "), err)
+		return false
+	}
+	defer stream.Close()
+
+	fmt.Println("Reading messages... (press Ctrl-C to quit)")
+	for {
+		reply, err := stream.Receive(ctx)
+		if ctx.Err() != nil {
+			return true
+		}
+		if err != nil && !reply.IsError() {
+			fmt.Fprintf(os.Stderr, "Connection closed: %v\n", err)
+			return false
+		}
+
+		fmt.Println(formatReply(reply, strings.ToUpper(arguments[0]), shell.raw))
+		if reply.IsError() {
+			return false
+		}
+	}
+}
+
+// runLines runs every line of reader as an inline command, skipping empty
+// lines and comments starting with '#'.
+func (shell *cli) runLines(reader io.Reader) bool {
+	success := true
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 512*1024*1024)
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		arguments, err := resp.SplitArguments(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Line %d: invalid argument(s): %v\n", lineNumber, err)
+			success = false
+			continue
+		}
+
+		success = shell.run(arguments) && success
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read commands: %v\n", err)
+		return false
+	}
+	return success
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"redigo/pkg/client"
+	"redigo/pkg/resp"
+)
+
+// fakeServer keeps the selected database and the queued transaction of each
+// connection, enough to tell whether the commands of a session share one.
+type fakeServer struct {
+	listener net.Listener
+	keys     map[string]string // Written values by "database/key"
+	mutex    sync.Mutex
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeServer{listener: listener, keys: map[string]string{}}
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(connection)
+		}
+	}()
+	return server
+}
+
+func (server *fakeServer) serve(connection net.Conn) {
+	defer connection.Close()
+
+	database, queued, inMulti := 0, [][]string{}, false
+	reader, writer := resp.NewReader(connection), resp.NewWriter(connection)
+
+	for {
+		arguments, _, err := reader.ReadCommand()
+		if err != nil {
+			return
+		}
+
+		var reply resp.Value
+		switch command := strings.ToUpper(arguments[0]); {
+		case command == "EXEC" && !inMulti:
+			reply = resp.NewError("USAGE EXEC without MULTI")
+		case command == "EXEC":
+			replies := []resp.Value{}
+			for _, queuedArguments := range queued {
+				replies = append(replies, server.set(database, queuedArguments))
+			}
+			reply, queued, inMulti = resp.NewArray(replies), nil, false
+		case inMulti:
+			reply, queued = resp.NewSimpleString("QUEUED"), append(queued, arguments)
+		case command == "MULTI":
+			reply, inMulti = resp.NewSimpleString("OK"), true
+		case command == "SELECT":
+			database, _ = strconv.Atoi(arguments[1])
+			reply = resp.NewSimpleString("OK")
+		case command == "SET":
+			reply = server.set(database, arguments)
+		default:
+			reply = resp.NewSimpleString("OK")
+		}
+
+		writer.WriteValue(reply)
+		writer.Flush()
+	}
+}
+
+func (server *fakeServer) set(database int, arguments []string) resp.Value {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	server.keys[strconv.Itoa(database)+"/"+arguments[1]] = arguments[2]
+	return resp.NewSimpleString("OK")
+}
+
+func (server *fakeServer) written() map[string]string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.keys
+}
+
+func TestSessionStateCarriesOver(t *testing.T) {
+	server := newFakeServer(t)
+	shell := newCli(client.New(client.Options{Address: server.listener.Addr().String(), PoolSize: 1}), false, "")
+	defer shell.close()
+
+	if !shell.runLines(strings.NewReader("SELECT 1\nSET k v\nMULTI\nSET a 1\nEXEC\n")) {
+		t.Fatal("a command of the file failed")
+	}
+
+	written := server.written()
+	if written["1/k"] != "v" || written["1/a"] != "1" || len(written) != 2 {
+		t.Errorf("wrote %v, expected k and a in database 1", written)
+	}
+}
+
+func TestDatabaseOption(t *testing.T) {
+	server := newFakeServer(t)
+	shell := newCli(client.New(client.Options{Address: server.listener.Addr().String(), Database: 3, PoolSize: 1}), false, "")
+	defer shell.close()
+
+	if !shell.run([]string{"SET", "k", "v"}) {
+		t.Fatal("SET failed")
+	}
+	if written := server.written(); written["3/k"] != "v" {
+		t.Errorf("wrote %v, expected k in database 3", written)
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+const (
+	HISTORY_FILENAME   = ".redigo_history"
+	MAX_HISTORY_LENGTH = 1000
+)
+
+// Commands whose line is not written to the history file, they carry passwords.
+var secretCommands = []string{"AUTH", "HELLO", "ACL"}
+
+type lineEditor struct {
+	input    *bufio.Reader
+	prompt   string
+	history  []string
+	complete func(prefix string) []string
+}
+
+func (shell *cli) repl() {
+	historyPath := ""
+	if homeDirectory, err := os.UserHomeDir(); err == nil {
+		historyPath = filepath.Join(homeDirectory, HISTORY_FILENAME)
+	}
+
+	editor := &lineEditor{
+		input:    bufio.NewReader(os.Stdin),
+		prompt:   shell.prompt,
+		history:  loadHistory(historyPath),
+		complete: shell.completeCommand,
+	}
+
+	for {
+		line, err := editor.readLine()
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if lowerLine := strings.ToLower(line); lowerLine == "quit" || lowerLine == "exit" {
+			return
+		}
+
+		arguments, err := resp.SplitArguments(line)
+		if err != nil {
+			fmt.Println("Invalid argument(s)")
+			continue
+		}
+
+		if !slices.Contains(secretCommands, strings.ToUpper(arguments[0])) {
+			editor.addHistory(line, historyPath)
+		}
+
+		shell.run(arguments)
+	}
+}
+
+// completeCommand returns the command names starting with prefix, fetched
+// from the server with COMMAND on first use.
+func (shell *cli) completeCommand(prefix string) []string {
+	if len(shell.commandNames) == 0 {
+		reply, err := shell.client.Do(context.Background(), "COMMAND")
+		if err != nil {
+			return []string{}
+		}
+
+		shell.commandNames = lo.FilterMap(reply.Array, func(command resp.Value, _ int) (string, bool) {
+			if len(command.Array) == 0 {
+				return "", false
+			}
+			return strings.ToUpper(command.Array[0].Str), true
+		})
+		slices.Sort(shell.commandNames)
+	}
+
+	lowercase := strings.IndexFunc(prefix, unicode.IsLower) >= 0
+
+	return lo.FilterMap(shell.commandNames, func(name string, _ int) (string, bool) {
+		if !strings.HasPrefix(name, strings.ToUpper(prefix)) {
+			return "", false
+		}
+		return lo.Ternary(lowercase, strings.ToLower(name), name), true
+	})
+}
+
+func loadHistory(path string) []string {
+	if path == "" {
+		return []string{}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []string{}
+	}
+
+	lines := lo.Compact(strings.Split(string(data), "\n"))
+	return lines[max(len(lines)-MAX_HISTORY_LENGTH, 0):]
+}
+
+func (editor *lineEditor) addHistory(line, path string) {
+	if len(editor.history) > 0 && editor.history[len(editor.history)-1] == line {
+		return
+	}
+
+	editor.history = append(editor.history, line)
+	if len(editor.history) > MAX_HISTORY_LENGTH {
+		editor.history = editor.history[1:]
+	}
+
+	if path == "" {
+		return
+	}
+
+	historyFile, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer historyFile.Close()
+	fmt.Fprintln(historyFile, line)
+}
+
+// readLine reads a line with the terminal in raw mode, supporting cursor
+// moves, history navigation and completion of the command name. It falls back
+// to plain line reading when the terminal cannot be switched to raw mode.
+func (editor *lineEditor) readLine() (string, error) {
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		fmt.Print(editor.prompt)
+		line, err := editor.input.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	defer restore()
+
+	line := []rune{}
+	cursor := 0
+	historyIndex := len(editor.history)
+	pendingLine := []rune{}
+	listCandidates := false
+
+	setLine := func(newLine []rune) {
+		line = slices.Clone(newLine)
+		cursor = len(line)
+	}
+
+	refresh := func() {
+		fmt.Print("\r", editor.prompt, string(line), "\x1b[K")
+		if cursor < len(line) {
+			fmt.Printf("\x1b[%dD", len(line)-cursor)
+		}
+	}
+
+	refresh()
+
+	for {
+		key, _, err := editor.input.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		tabPressed := false
+
+		switch key {
+		case '\r', '\n':
+			fmt.Print("\n")
+			return string(line), nil
+		case 3: // Ctrl-C
+			fmt.Print("^C\n")
+			line, cursor = []rune{}, 0
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Print("\n")
+				return "", io.EOF
+			}
+			if cursor < len(line) {
+				line = slices.Delete(line, cursor, cursor+1)
+			}
+		case 127, 8: // Backspace
+			if cursor > 0 {
+				line = slices.Delete(line, cursor-1, cursor)
+				cursor--
+			}
+		case 1: // Ctrl-A
+			cursor = 0
+		case 5: // Ctrl-E
+			cursor = len(line)
+		case 11: // Ctrl-K
+			line = line[:cursor]
+		case 21: // Ctrl-U
+			line, cursor = slices.Clone(line[cursor:]), 0
+		case 23: // Ctrl-W
+			start := cursor
+			for start > 0 && line[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && line[start-1] != ' ' {
+				start--
+			}
+			line, cursor = slices.Delete(line, start, cursor), start
+		case 12: // Ctrl-L
+			fmt.Print("\x1b[H\x1b[2J")
+		case '\t':
+			tabPressed = true
+			line, cursor = editor.completeLine(line, cursor, listCandidates)
+		case 27: // Escape sequences of the arrow, home, end and delete keys
+			switch editor.readEscapeSequence() {
+			case "A":
+				if historyIndex > 0 {
+					if historyIndex == len(editor.history) {
+						pendingLine = slices.Clone(line)
+					}
+					historyIndex--
+					setLine([]rune(editor.history[historyIndex]))
+				}
+			case "B":
+				if historyIndex < len(editor.history) {
+					historyIndex++
+					if historyIndex == len(editor.history) {
+						setLine(pendingLine)
+					} else {
+						setLine([]rune(editor.history[historyIndex]))
+					}
+				}
+			case "C":
+				cursor = min(cursor+1, len(line))
+			case "D":
+				cursor = max(cursor-1, 0)
+			case "H", "1~", "7~":
+				cursor = 0
+			case "F", "4~", "8~":
+				cursor = len(line)
+			case "3~":
+				if cursor < len(line) {
+					line = slices.Delete(line, cursor, cursor+1)
+				}
+			}
+		default:
+			if unicode.IsPrint(key) {
+				line = slices.Insert(line, cursor, key)
+				cursor++
+			}
+		}
+
+		listCandidates = tabPressed
+		refresh()
+	}
+}
+
+func (editor *lineEditor) readEscapeSequence() string {
+	introducer, _, err := editor.input.ReadRune()
+	if err != nil || (introducer != '[' && introducer != 'O') {
+		return ""
+	}
+
+	sequence := ""
+	for {
+		character, _, err := editor.input.ReadRune()
+		if err != nil {
+			return ""
+		}
+
+		sequence += string(character)
+		if character < '0' || character > '9' {
+			return sequence
+		}
+	}
+}
+
+// completeLine completes the command name under the cursor with the longest
+// prefix shared by the candidates. When it cannot go further, a second tab
+// lists the candidates.
+func (editor *lineEditor) completeLine(line []rune, cursor int, listCandidates bool) ([]rune, int) {
+	prefix := string(line[:cursor])
+	if strings.ContainsRune(prefix, ' ') {
+		fmt.Print("\a")
+		return line, cursor
+	}
+
+	candidates := editor.complete(prefix)
+	if len(candidates) == 0 {
+		fmt.Print("\a")
+		return line, cursor
+	}
+
+	if len(candidates) == 1 {
+		completion := []rune(candidates[0] + " ")
+		return append(completion, line[cursor:]...), len(completion)
+	}
+
+	common := lo.Reduce(candidates[1:], func(common string, candidate string, _ int) string {
+		for !strings.HasPrefix(candidate, common) {
+			common = common[:len(common)-1]
+		}
+		return common
+	}, candidates[0])
+
+	if len(common) > len(prefix) {
+		completion := []rune(common)
+		return append(completion, line[cursor:]...), len(completion)
+	}
+
+	if listCandidates {
+		fmt.Print("\n", strings.Join(candidates, "  "), "\n")
+	} else {
+		fmt.Print("\a")
+	}
+	return line, cursor
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"redigo/internal/acl"
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+)
+
+const (
+	ACL_LIST_SUBCOMMAND    = "LIST"    // Describe every user as ACL rules
+	ACL_USERS_SUBCOMMAND   = "USERS"   // List the user names
+	ACL_SETUSER_SUBCOMMAND = "SETUSER" // Create or modify a user
+	ACL_DELUSER_SUBCOMMAND = "DELUSER" // Delete users
+	ACL_WHOAMI_SUBCOMMAND  = "WHOAMI"  // Get the user of the current connection
+)
+
+func (session *Session) Username() string {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	return session.username
+}
+
+func (session *Session) SetUsername(username string) {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	session.username = username
+}
+
+// authorizeCommand checks the ACL rules of the session user before a command is dispatched.
+func authorizeCommand(command *Command, arguments []string, session *Session) error {
+	username := session.Username()
+
+	if username == "" {
+		if command.HasFlag(NOAUTH_FLAG) {
+			return nil
+		}
+		return redigoErrors.ErrorAuthenticationRequired
+	}
+
+	if slices.Contains(command.FlagsFor(arguments), CONNECTION_FLAG) {
+		return nil
+	}
+
+	requiresAllKeys := slices.Contains(command.FlagsFor(arguments), ADMIN_FLAG)
+	return session.server.acl.Check(username, command.Name, command.Keys(arguments), requiresAllKeys)
+}
+
+func authenticate(session *Session, username, password string) error {
+	if err := session.server.acl.Authenticate(username, password); err != nil {
+		return err
+	}
+
+	session.SetUsername(username)
+	return nil
+}
+
+func handleAuthCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	var username, password string
+
+	switch len(arguments) {
+	case 2:
+		username, password = acl.DEFAULT_USERNAME, arguments[1]
+	case 3:
+		username, password = arguments[1], arguments[2]
+	default:
+		return NewUsageErrorResponse("Usage: AUTH [username] {password}")
+	}
+
+	if err := authenticate(session, username, password); err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse("OK")
+}
+
+func handleAclCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	switch strings.ToUpper(arguments[1]) {
+	case ACL_LIST_SUBCOMMAND:
+		rules := session.server.acl.Describe()
+		return NewSuccessResponse(strings.Join(rules, "\n")).WithReply(resp.NewBulkStringArray(rules))
+	case ACL_USERS_SUBCOMMAND:
+		usernames := session.server.acl.Usernames()
+		return NewSuccessResponse(strings.Join(usernames, "\n")).WithReply(resp.NewBulkStringArray(usernames))
+	case ACL_SETUSER_SUBCOMMAND:
+		if len(arguments) < 3 {
+			return NewUsageErrorResponse("Usage: ACL SETUSER {username} [rule ...]")
+		}
+		if err := session.server.acl.SetUser(arguments[2], arguments[3:]); err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to set user: %w", err))
+		}
+		return NewSuccessResponse("OK")
+	case ACL_DELUSER_SUBCOMMAND:
+		if len(arguments) < 3 {
+			return NewUsageErrorResponse("Usage: ACL DELUSER {username} [username ...]")
+		}
+		deleted, err := session.server.acl.DeleteUsers(arguments[2:])
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to delete users: %w", err))
+		}
+		return NewIntegerResponse(int64(deleted))
+	case ACL_WHOAMI_SUBCOMMAND:
+		return NewBulkResponse(session.Username())
+	default:
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown ACL subcommand '%v'", arguments[1]))
+	}
+}
+
+func init() {
+	RegisterCommand(Command{
+		Name:    AUTH_COMMAND,
+		Arity:   -2,
+		Flags:   []CommandFlag{NOAUTH_FLAG},
+		Usage:   "AUTH [username] {password}",
+		Handler: handleAuthCommand,
+	})
+	RegisterCommand(Command{
+		Name:    ACL_COMMAND,
+		Arity:   -2,
+		Flags:   []CommandFlag{ADMIN_FLAG},
+		Usage:   "ACL LIST|USERS|SETUSER|DELUSER|WHOAMI",
+		Handler: handleAclCommand,
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"redigo/internal/acl"
+	redigoErrors "redigo/internal/redigo/errors"
+)
+
+func TestAdminCommandsRequireAllKeys(t *testing.T) {
+	manager, err := acl.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetUser("tenant", []string{"on", "nopass", "~tenant:*", "+@all"}); err != nil {
+		t.Fatal(err)
+	}
+
+	session := &Session{server: &Server{acl: manager}, username: "tenant"}
+
+	for _, arguments := range [][]string{{"FLUSHDB"}, {"FLUSHALL"}, {"SWAPDB", "0", "1"}, {"MONITOR"}} {
+		command, _ := LookupCommand(arguments[0])
+		if err := authorizeCommand(command, arguments, session); !errors.Is(err, redigoErrors.ErrorNoPermission) {
+			t.Errorf("%s returned %v, expected ErrorNoPermission", arguments[0], err)
+		}
+	}
+
+	command, _ := LookupCommand(SET_COMMAND)
+	if err := authorizeCommand(command, []string{"SET", "tenant:1", "value"}, session); err != nil {
+		t.Errorf("SET tenant:1 denied: %v", err)
+	}
+
+	session.username = acl.DEFAULT_USERNAME
+	command, _ = LookupCommand(FLUSHALL_COMMAND)
+	if err := authorizeCommand(command, []string{"FLUSHALL"}, session); err != nil {
+		t.Errorf("FLUSHALL denied to the default user: %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+	"redigo/pkg/utils"
+
+	"github.com/samber/lo"
+)
+
+const (
+	CLIENT_LIST_SUBCOMMAND    = "LIST"    // Describe every connected client
+	CLIENT_KILL_SUBCOMMAND    = "KILL"    // Close a client connection
+	CLIENT_SETNAME_SUBCOMMAND = "SETNAME" // Name the current connection
+	CLIENT_GETNAME_SUBCOMMAND = "GETNAME" // Get the name of the current connection
+	CLIENT_ID_SUBCOMMAND      = "ID"      // Get the id of the current connection
+)
+
+// CLIENT_NILERRORS_SUBCOMMAND makes the connection answer misses with their
+// error, such as NOTFOUND or EXPIRED, instead of a nil reply without a code.
+const CLIENT_NILERRORS_SUBCOMMAND = "NILERRORS"
+
+const IDLE_CLIENTS_CHECK_INTERVAL = time.Second
+
+func (session *Session) recordCommand(commandName string) {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	session.lastInteraction = time.Now()
+	session.lastCommand = strings.ToLower(commandName)
+}
+
+func (session *Session) Name() string {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	return session.name
+}
+
+func (session *Session) SetName(name string) {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	session.name = name
+}
+
+func (session *Session) IdleTime() time.Duration {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	return time.Since(session.lastInteraction)
+}
+
+// Address returns the address of the client, "http" for the HTTP gateway. Unix
+// socket clients have no address of their own, the socket path is suffixed by
+// the client id so that CLIENT KILL can tell them apart.
+func (session *Session) Address() string {
+	if session.connection == nil {
+		return "http"
+	}
+
+	if session.connection.RemoteAddr().Network() == "unix" {
+		return fmt.Sprintf("%s:%d", session.connection.LocalAddr().String(), session.id)
+	}
+	return session.connection.RemoteAddr().String()
+}
+
+// Describe formats the session the way CLIENT LIST reports it.
+func (session *Session) Describe() string {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	now := time.Now()
+
+	return fmt.Sprintf(
+		"id=%d addr=%s name=%s age=%d idle=%d db=%d cmd=%s user=%s",
+		session.id,
+		session.Address(),
+		session.name,
+		int64(now.Sub(session.createdAt).Seconds()),
+		int64(now.Sub(session.lastInteraction).Seconds()),
+		session.db,
+		lo.Ternary(session.lastCommand == "", "NULL", session.lastCommand),
+		session.username,
+	)
+}
+
+// Kill closes the connection. When a session kills itself the connection is
+// closed after its reply has been written.
+func (session *Session) Kill(requester *Session) {
+	if session == requester {
+		session.informationMutex.Lock()
+		session.closeAfterReply = true
+		session.informationMutex.Unlock()
+		return
+	}
+
+	session.connection.Close()
+}
+
+func (session *Session) shouldClose() bool {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	return session.closeAfterReply
+}
+
+func (server *Server) StartIdleClientsListener() {
+	if server.config.ClientIdleTimeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(IDLE_CLIENTS_CHECK_INTERVAL)
+	defer ticker.Stop()
+
+	closeIdleSessions := func() {
+		idleSessions := lo.Filter(
+			server.Sessions(),
+			func(session *Session, _ int) bool {
+				return !session.isSubscribed() && !session.isMonitoring() && session.IdleTime() > server.config.ClientIdleTimeout
+			},
+		)
+
+		lo.ForEach(idleSessions, func(session *Session, _ int) {
+			session.connection.Close()
+		})
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			closeIdleSessions()
+		case <-server.stopped:
+			return
+		}
+	}
+}
+
+func handleClientCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	switch strings.ToUpper(arguments[1]) {
+	case CLIENT_LIST_SUBCOMMAND:
+		return handleClientListCommand(arguments, session)
+	case CLIENT_KILL_SUBCOMMAND:
+		return handleClientKillCommand(arguments, session)
+	case CLIENT_SETNAME_SUBCOMMAND:
+		return handleClientSetnameCommand(arguments, session)
+	case CLIENT_GETNAME_SUBCOMMAND:
+		return handleClientGetnameCommand(arguments, session)
+	case CLIENT_ID_SUBCOMMAND:
+		return handleClientIdCommand(arguments, session)
+	case CLIENT_NILERRORS_SUBCOMMAND:
+		return handleClientNilerrorsCommand(arguments, session)
+	default:
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown CLIENT subcommand '%v'", arguments[1]))
+	}
+}
+
+func handleClientListCommand(arguments []string, session *Session) ClientResponse {
+	if len(arguments) != 2 {
+		return NewUsageErrorResponse("Usage: CLIENT LIST")
+	}
+
+	descriptions := lo.Map(
+		session.server.Sessions(),
+		func(client *Session, _ int) string {
+			return client.Describe() + "\n"
+		},
+	)
+
+	list := strings.Join(descriptions, "")
+	return NewSuccessResponse(strings.TrimSuffix(list, "\n")).WithReply(resp.NewBulkString(list))
+}
+
+func handleClientKillCommand(arguments []string, session *Session) ClientResponse {
+	usage := "Usage: CLIENT KILL {addr} | CLIENT KILL ID {id} | CLIENT KILL ADDR {addr}"
+
+	switch len(arguments) {
+	case 3:
+		target, found := lo.Find(
+			session.server.Sessions(),
+			func(client *Session) bool {
+				return client.Address() == arguments[2]
+			},
+		)
+		if !found {
+			return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "no such client"))
+		}
+
+		target.Kill(session)
+		return NewSuccessResponse("OK")
+	case 4:
+		var matches func(client *Session) bool
+
+		switch strings.ToUpper(arguments[2]) {
+		case "ID":
+			id, err := utils.FromStringToInt64(arguments[3])
+			if err != nil {
+				return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "invalid client id '%v'", arguments[3]))
+			}
+			matches = func(client *Session) bool { return client.id == id }
+		case "ADDR":
+			matches = func(client *Session) bool { return client.Address() == arguments[3] }
+		default:
+			return NewUsageErrorResponse(usage)
+		}
+
+		targets := lo.Filter(
+			session.server.Sessions(),
+			func(client *Session, _ int) bool {
+				return matches(client)
+			},
+		)
+
+		lo.ForEach(targets, func(target *Session, _ int) {
+			target.Kill(session)
+		})
+		return NewIntegerResponse(int64(len(targets)))
+	default:
+		return NewUsageErrorResponse(usage)
+	}
+}
+
+func handleClientSetnameCommand(arguments []string, session *Session) ClientResponse {
+	if len(arguments) != 3 {
+		return NewUsageErrorResponse("Usage: CLIENT SETNAME {name}")
+	}
+
+	if strings.ContainsAny(arguments[2], " \n\r\t") {
+		return NewErrorResponse(
+			redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "client names cannot contain spaces, newlines or special characters"),
+		)
+	}
+
+	session.SetName(arguments[2])
+	return NewSuccessResponse("OK")
+}
+
+func handleClientGetnameCommand(arguments []string, session *Session) ClientResponse {
+	if len(arguments) != 2 {
+		return NewUsageErrorResponse("Usage: CLIENT GETNAME")
+	}
+
+	name := session.Name()
+	if name == "" {
+		return NewSuccessResponse("(nil)").WithReply(resp.NewNull())
+	}
+	return NewBulkResponse(name)
+}
+
+func handleClientIdCommand(arguments []string, session *Session) ClientResponse {
+	if len(arguments) != 2 {
+		return NewUsageErrorResponse("Usage: CLIENT ID")
+	}
+
+	return NewIntegerResponse(session.id)
+}
+
+func handleClientNilerrorsCommand(arguments []string, session *Session) ClientResponse {
+	if len(arguments) != 3 || !lo.Contains([]string{"ON", "OFF"}, strings.ToUpper(arguments[2])) {
+		return NewUsageErrorResponse("Usage: CLIENT NILERRORS ON|OFF")
+	}
+
+	session.writerMutex.Lock()
+	session.nilErrors = strings.ToUpper(arguments[2]) == "ON"
+	session.writerMutex.Unlock()
+
+	return NewSuccessResponse("OK")
+}
+
+func init() {
+	RegisterCommand(Command{
+		Name:    CLIENT_COMMAND,
+		Arity:   -2,
+		Flags:   []CommandFlag{ADMIN_FLAG},
+		Usage:   "CLIENT LIST|KILL|SETNAME|GETNAME|ID|NILERRORS",
+		Handler: handleClientCommand,
+		SubcommandFlags: map[string][]CommandFlag{
+			CLIENT_SETNAME_SUBCOMMAND:   {CONNECTION_FLAG},
+			CLIENT_GETNAME_SUBCOMMAND:   {CONNECTION_FLAG},
+			CLIENT_ID_SUBCOMMAND:        {CONNECTION_FLAG},
+			CLIENT_NILERRORS_SUBCOMMAND: {CONNECTION_FLAG},
+		},
+	})
+}
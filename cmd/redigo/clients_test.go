@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"redigo/internal/acl"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+func TestClientConnectionSubcommandsSkipAcl(t *testing.T) {
+	manager, err := acl.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetUser("reader", []string{"on", "nopass", "~*", "+get"}); err != nil {
+		t.Fatal(err)
+	}
+
+	session := &Session{server: &Server{acl: manager}, username: "reader"}
+	command, _ := LookupCommand(CLIENT_COMMAND)
+
+	for _, subcommand := range []string{"SETNAME", "getname", "ID"} {
+		if err := authorizeCommand(command, []string{"CLIENT", subcommand, "name"}, session); err != nil {
+			t.Errorf("CLIENT %s denied: %v", subcommand, err)
+		}
+	}
+
+	for _, subcommand := range []string{"LIST", "KILL"} {
+		if err := authorizeCommand(command, []string{"CLIENT", subcommand}, session); !errors.Is(err, redigoErrors.ErrorNoPermission) {
+			t.Errorf("CLIENT %s returned %v, expected ErrorNoPermission", subcommand, err)
+		}
+	}
+
+	session.username = ""
+	if err := authorizeCommand(command, []string{"CLIENT", "ID"}, session); !errors.Is(err, redigoErrors.ErrorAuthenticationRequired) {
+		t.Errorf("CLIENT ID before AUTH returned %v, expected ErrorAuthenticationRequired", err)
+	}
+}
+
+func TestUnixClientAddressesAreUnique(t *testing.T) {
+	listener, err := net.Listen("unix", filepath.Join(t.TempDir(), "redigo.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	addresses := map[string]bool{}
+	for id := int64(1); id <= 2; id++ {
+		client, err := net.Dial("unix", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		connection, err := listener.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer connection.Close()
+
+		addresses[(&Session{id: id, connection: connection}).Address()] = true
+	}
+
+	if len(addresses) != 2 {
+		t.Errorf("unix clients share their address: %v", addresses)
+	}
+}
+
+func TestClientNilerrors(t *testing.T) {
+	var buffer bytes.Buffer
+	session := &Session{writer: resp.NewWriter(&buffer)}
+	miss := NewNilResponse(redigoErrors.Wrapf(redigoErrors.ErrorKeyExpired, "failed to get value: key.expired"))
+
+	for _, mode := range []string{"ON", "OFF"} {
+		if response := handleClientNilerrorsCommand([]string{"CLIENT", "NILERRORS", mode}, session); !response.Success {
+			t.Fatal(response.Message)
+		}
+
+		buffer.Reset()
+		writeResponse(session, miss)
+		session.writer.Flush()
+
+		expected := lo.Ternary(mode == "ON", "-EXPIRED failed to get value: key.expired\r\n", "$-1\r\n")
+		if buffer.String() != expected {
+			t.Errorf("miss with NILERRORS %s written as %q, expected %q", mode, buffer.String(), expected)
+		}
+	}
+}
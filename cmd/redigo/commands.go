@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+type CommandFlag string
+
+const (
+	WRITE_FLAG    CommandFlag = "write"    // Modifies the dataset
+	READONLY_FLAG CommandFlag = "readonly" // Only reads the dataset
+	ADMIN_FLAG    CommandFlag = "admin"    // Manages the server, its clients or its users, requires access to all keys
+	NOAUTH_FLAG   CommandFlag = "noauth"   // Allowed before the connection is authenticated
+	PUBSUB_FLAG   CommandFlag = "pubsub"   // Allowed while a RESP2 connection is subscribed
+	NOMULTI_FLAG  CommandFlag = "no-multi" // Cannot be queued in a transaction
+)
+
+// CONNECTION_FLAG marks subcommands which only affect the calling connection,
+// every authenticated user can run them whatever its ACL rules.
+const CONNECTION_FLAG CommandFlag = "connection"
+
+const (
+	COMMAND_INFO_SUBCOMMAND  = "INFO"  // Describe the given commands
+	COMMAND_COUNT_SUBCOMMAND = "COUNT" // Get the number of commands
+)
+
+type CommandHandler func(arguments []string, session *Session, store redigo.Store) ClientResponse
+
+type Command struct {
+	Name     string        // Uppercase name clients send
+	Arity    int           // Number of arguments including the name, negative for a minimum
+	Flags    []CommandFlag // Behaviour of the command
+	FirstKey int           // Position of the first key argument, 0 when the command has no keys
+	LastKey  int           // Position of the last key argument, negative to count from the end
+	KeyStep  int           // Distance between two key arguments
+	Usage    string        // Syntax reported on usage errors
+	Handler  CommandHandler
+
+	SubcommandFlags map[string][]CommandFlag // Flags replacing Flags for the given uppercase subcommands
+}
+
+var commands = map[string]*Command{}
+
+// RegisterCommand adds command to the registry, registering the same name twice is a programming error.
+func RegisterCommand(command Command) {
+	name := strings.ToUpper(command.Name)
+	if lo.HasKey(commands, name) {
+		panic(fmt.Sprintf("command '%s' is already registered", name))
+	}
+
+	command.Name = name
+	commands[name] = &command
+}
+
+func LookupCommand(name string) (*Command, bool) {
+	command, exists := commands[strings.ToUpper(name)]
+	return command, exists
+}
+
+// SortedCommands returns every registered command ordered by name.
+func SortedCommands() []*Command {
+	names := lo.Keys(commands)
+	slices.Sort(names)
+
+	return lo.Map(names, func(name string, _ int) *Command {
+		return commands[name]
+	})
+}
+
+func (command *Command) HasFlag(flag CommandFlag) bool {
+	return slices.Contains(command.Flags, flag)
+}
+
+// FlagsFor returns the flags of the subcommand in arguments when it has its own, the command flags otherwise.
+func (command *Command) FlagsFor(arguments []string) []CommandFlag {
+	if len(arguments) < 2 {
+		return command.Flags
+	}
+
+	if flags, exists := command.SubcommandFlags[strings.ToUpper(arguments[1])]; exists {
+		return flags
+	}
+	return command.Flags
+}
+
+// AcceptsArity reports whether numberOfArguments, name included, matches the command arity.
+func (command *Command) AcceptsArity(numberOfArguments int) bool {
+	if command.Arity < 0 {
+		return numberOfArguments >= -command.Arity
+	}
+	return numberOfArguments == command.Arity
+}
+
+// Keys extracts the key arguments of arguments using the command key positions.
+func (command *Command) Keys(arguments []string) []string {
+	if command.FirstKey <= 0 || command.KeyStep <= 0 {
+		return []string{}
+	}
+
+	lastKey := command.LastKey
+	if lastKey < 0 {
+		lastKey += len(arguments)
+	}
+
+	keys := []string{}
+	for position := command.FirstKey; position <= lastKey && position < len(arguments); position += command.KeyStep {
+		keys = append(keys, arguments[position])
+	}
+	return keys
+}
+
+// Describe formats the command the way COMMAND reports it: name, arity, flags,
+// first key, last key, key step and usage.
+func (command *Command) Describe() resp.Value {
+	flags := lo.Map(command.Flags, func(flag CommandFlag, _ int) resp.Value {
+		return resp.NewSimpleString(string(flag))
+	})
+
+	return resp.NewArray([]resp.Value{
+		resp.NewBulkString(strings.ToLower(command.Name)),
+		resp.NewInteger(int64(command.Arity)),
+		resp.NewArray(flags),
+		resp.NewInteger(int64(command.FirstKey)),
+		resp.NewInteger(int64(command.LastKey)),
+		resp.NewInteger(int64(command.KeyStep)),
+		resp.NewBulkString(command.Usage),
+	})
+}
+
+func (command *Command) String() string {
+	flags := lo.Map(command.Flags, func(flag CommandFlag, _ int) string { return string(flag) })
+
+	return fmt.Sprintf(
+		"%s arity=%d flags=%s keys=%d:%d:%d usage=%s",
+		strings.ToLower(command.Name),
+		command.Arity,
+		strings.Join(flags, ","),
+		command.FirstKey,
+		command.LastKey,
+		command.KeyStep,
+		command.Usage,
+	)
+}
+
+func newCommandsResponse(described []*Command) ClientResponse {
+	lines := lo.Map(described, func(command *Command, _ int) string {
+		if command == nil {
+			return "(nil)"
+		}
+		return command.String()
+	})
+
+	replies := lo.Map(described, func(command *Command, _ int) resp.Value {
+		if command == nil {
+			return resp.NewNull()
+		}
+		return command.Describe()
+	})
+
+	return NewSuccessResponse(strings.Join(lines, "\n")).WithReply(resp.NewArray(replies))
+}
+
+func handleCommandCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if len(arguments) == 1 {
+		return newCommandsResponse(SortedCommands())
+	}
+
+	switch strings.ToUpper(arguments[1]) {
+	case COMMAND_COUNT_SUBCOMMAND:
+		if len(arguments) != 2 {
+			return NewUsageErrorResponse("Usage: COMMAND COUNT")
+		}
+		return NewIntegerResponse(int64(len(commands)))
+	case COMMAND_INFO_SUBCOMMAND:
+		if len(arguments) == 2 {
+			return newCommandsResponse(SortedCommands())
+		}
+
+		described := lo.Map(arguments[2:], func(name string, _ int) *Command {
+			command, _ := LookupCommand(name)
+			return command
+		})
+		return newCommandsResponse(described)
+	default:
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown COMMAND subcommand '%v'", arguments[1]))
+	}
+}
+
+func init() {
+	RegisterCommand(Command{
+		Name:    COMMAND_COMMAND,
+		Arity:   -1,
+		Usage:   "COMMAND [COUNT|INFO [command ...]]",
+		Handler: handleCommandCommand,
+	})
+}
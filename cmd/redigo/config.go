@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"redigo/envs"
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+	"redigo/pkg/utils"
+
+	"github.com/samber/lo"
+)
+
+const (
+	CONFIG_GET_SUBCOMMAND     = "GET"     // Get the parameters matching glob patterns
+	CONFIG_SET_SUBCOMMAND     = "SET"     // Change parameters at runtime
+	CONFIG_REWRITE_SUBCOMMAND = "REWRITE" // Write the current parameters to the .env file
+)
+
+// configParameter is a setting that CONFIG can change at runtime, named after
+// its environment variable.
+type configParameter struct {
+	name  string
+	get   func(config redigo.Config) string
+	parse func(value string) (redigo.Option, error)
+}
+
+func durationParameter(name string, get func(config redigo.Config) time.Duration, option func(time.Duration) redigo.Option) configParameter {
+	return configParameter{
+		name: name,
+		get:  func(config redigo.Config) string { return get(config).String() },
+		parse: func(value string) (redigo.Option, error) {
+			interval, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, err
+			}
+			return option(interval), nil
+		},
+	}
+}
+
+var configParameters = []configParameter{
+	durationParameter(
+		"SNAPSHOT_SAVE_INTERVAL",
+		func(config redigo.Config) time.Duration { return config.SnapshotSaveInterval },
+		redigo.WithSnapshotInterval,
+	),
+	durationParameter(
+		"FLUSH_BUFFER_INTERVAL",
+		func(config redigo.Config) time.Duration { return config.FlushBufferInterval },
+		redigo.WithFlushBufferInterval,
+	),
+	durationParameter(
+		"DATA_EXPIRATION_INTERVAL",
+		func(config redigo.Config) time.Duration { return config.DataExpirationInterval },
+		redigo.WithExpirationInterval,
+	),
+	{
+		name: "DEFAULT_TTL",
+		get:  func(config redigo.Config) string { return strconv.FormatInt(config.DefaultTtl, 10) },
+		parse: func(value string) (redigo.Option, error) {
+			seconds, err := utils.FromStringToInt64(value)
+			if err != nil {
+				return nil, err
+			}
+			return redigo.WithDefaultTTL(time.Duration(seconds) * time.Second), nil
+		},
+	},
+	{
+		name: "MAX_MEMORY",
+		get:  func(config redigo.Config) string { return strconv.FormatInt(config.MaxMemory, 10) },
+		parse: func(value string) (redigo.Option, error) {
+			bytes, err := utils.FromStringToInt64(value)
+			if err != nil {
+				return nil, err
+			}
+			return redigo.WithMaxMemory(bytes), nil
+		},
+	},
+	{
+		name: "MAX_MEMORY_POLICY",
+		get:  func(config redigo.Config) string { return string(config.MaxMemoryPolicy) },
+		parse: func(value string) (redigo.Option, error) {
+			return redigo.WithMaxMemoryPolicy(redigo.EvictionPolicy(strings.ToLower(value))), nil
+		},
+	},
+}
+
+func lookupConfigParameter(name string) (configParameter, bool) {
+	return lo.Find(configParameters, func(parameter configParameter) bool {
+		return strings.EqualFold(parameter.name, name)
+	})
+}
+
+func handleConfigGet(arguments []string, session *Session) ClientResponse {
+	config := session.server.database.Config()
+
+	matching := lo.Filter(configParameters, func(parameter configParameter, _ int) bool {
+		return lo.SomeBy(arguments[2:], func(pattern string) bool {
+			return utils.MatchGlob(strings.ToUpper(pattern), parameter.name)
+		})
+	})
+
+	lines := lo.Map(matching, func(parameter configParameter, _ int) string {
+		return parameter.name + " " + parameter.get(config)
+	})
+	pairs := lo.FlatMap(matching, func(parameter configParameter, _ int) []resp.Value {
+		return []resp.Value{resp.NewBulkString(parameter.name), resp.NewBulkString(parameter.get(config))}
+	})
+
+	return NewSuccessResponse(strings.Join(lines, "\n")).WithReply(resp.NewMap(pairs...))
+}
+
+// handleConfigSet applies every name value pair at once, nothing changes if
+// one of them is invalid.
+func handleConfigSet(arguments []string, session *Session) ClientResponse {
+	if len(arguments)%2 != 0 {
+		return NewUsageErrorResponse("Usage: CONFIG SET {parameter} {value} [parameter value ...]")
+	}
+
+	options := []redigo.Option{}
+	for _, pair := range lo.Chunk(arguments[2:], 2) {
+		parameter, exists := lookupConfigParameter(pair[0])
+		if !exists {
+			return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "unknown parameter '%v'", pair[0]))
+		}
+
+		option, err := parameter.parse(pair[1])
+		if err != nil {
+			return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "invalid value '%v' for '%s'", pair[1], parameter.name))
+		}
+		options = append(options, option)
+	}
+
+	if err := session.server.database.Reconfigure(options...); err != nil {
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "%v", err))
+	}
+	return NewSuccessResponse("OK")
+}
+
+func handleConfigRewrite(arguments []string, session *Session) ClientResponse {
+	config := session.server.database.Config()
+
+	variables := lo.SliceToMap(configParameters, func(parameter configParameter) (string, string) {
+		return parameter.name, parameter.get(config)
+	})
+
+	if err := envs.Rewrite(variables); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to rewrite configuration: %w", err))
+	}
+	return NewSuccessResponse("OK")
+}
+
+func handleConfigCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	switch strings.ToUpper(arguments[1]) {
+	case CONFIG_GET_SUBCOMMAND:
+		if len(arguments) < 3 {
+			return NewUsageErrorResponse("Usage: CONFIG GET {pattern} [pattern ...]")
+		}
+		return handleConfigGet(arguments, session)
+	case CONFIG_SET_SUBCOMMAND:
+		if len(arguments) < 4 {
+			return NewUsageErrorResponse("Usage: CONFIG SET {parameter} {value} [parameter value ...]")
+		}
+		return handleConfigSet(arguments, session)
+	case CONFIG_REWRITE_SUBCOMMAND:
+		return handleConfigRewrite(arguments, session)
+	default:
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown CONFIG subcommand '%v'", arguments[1]))
+	}
+}
+
+func init() {
+	RegisterCommand(Command{
+		Name:    CONFIG_COMMAND,
+		Arity:   -2,
+		Flags:   []CommandFlag{ADMIN_FLAG},
+		Usage:   "CONFIG GET {pattern} [pattern ...]|SET {parameter} {value} [parameter value ...]|REWRITE",
+		Handler: handleConfigCommand,
+	})
+}
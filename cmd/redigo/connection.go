@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"redigo/internal/pubsub"
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+)
+
+type Session struct {
+	id         int64        // Unique identifier returned by CLIENT ID
+	connection net.Conn     // Underlying client connection
+	reader     *resp.Reader // Parses RESP arrays and inline commands
+	writer     *resp.Writer // Serializes replies, holds the negotiated RESP version
+	isInline   bool         // Whether the request being served used the inline protocol, protected by writerMutex
+	nilErrors  bool         // Set by CLIENT NILERRORS, misses are answered with their error instead of nil, protected by writerMutex
+	server     *Server      // Server that accepted the connection
+	createdAt  time.Time    // When the connection was accepted
+
+	name             string     // Name set with CLIENT SETNAME
+	username         string     // ACL user the connection is authenticated as, empty before AUTH
+	lastInteraction  time.Time  // When the last command was received
+	lastCommand      string     // Name of the last command received
+	closeAfterReply  bool       // Set by CLIENT KILL targeting the session itself
+	informationMutex sync.Mutex // Protects the fields above, read by CLIENT LIST from other connections
+
+	pubsubSubscriber  *pubsub.Subscriber // Created on the first subscription, protected by informationMutex
+	monitorSubscriber *pubsub.Subscriber // Created by MONITOR, protected by informationMutex
+	writerMutex       sync.Mutex         // Serializes replies and the messages pushed to subscribers and monitors
+
+	transaction *pendingTransaction // Commands queued since MULTI, nil outside of a transaction
+	watcher     *redigo.Watcher     // Keys watched for the next transaction, created by the first WATCH
+
+	db int // Logical database selected with SELECT, written under informationMutex for CLIENT LIST
+}
+
+func NewSession(id int64, connection net.Conn, server *Server) *Session {
+	reader := resp.NewReader(connection)
+	reader.MaxSize = server.config.MaxValueSize
+	now := time.Now()
+
+	return &Session{
+		id:              id,
+		connection:      connection,
+		reader:          reader,
+		writer:          resp.NewWriter(connection),
+		server:          server,
+		createdAt:       now,
+		lastInteraction: now,
+		username:        server.acl.DefaultUser(),
+	}
+}
+
+// writeResponse only buffers the reply, HandleConnection flushes once the
+// pipelined batch of commands has been fully processed.
+func writeResponse(session *Session, response ClientResponse) error {
+	if session == nil {
+		fmt.Print(response.ToString())
+		return nil
+	}
+
+	if response.written {
+		return nil
+	}
+
+	session.writerMutex.Lock()
+	defer session.writerMutex.Unlock()
+
+	return session.unsafeWriteResponse(response)
+}
+
+// unsafeWriteResponse buffers the reply, the caller holds writerMutex.
+func (session *Session) unsafeWriteResponse(response ClientResponse) error {
+	if session.isInline {
+		return session.writer.WriteInline(response.ToString())
+	}
+
+	if session.nilErrors && !response.Success && response.Error != nil && response.Reply.IsNull() {
+		return session.writer.WriteValue(resp.NewError(response.Message))
+	}
+
+	if len(response.Pushes) > 0 {
+		for _, push := range response.Pushes {
+			if err := session.writer.WriteValue(push); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return session.writer.WriteValue(response.Reply)
+}
+
+func (session *Session) flush() error {
+	session.writerMutex.Lock()
+	defer session.writerMutex.Unlock()
+
+	return session.writer.Flush()
+}
+
+// requirePersistentConnection rejects the commands keeping state across
+// requests when they are sent through the HTTP gateway.
+func requirePersistentConnection(arguments []string, session *Session) *ClientResponse {
+	if session.connection != nil {
+		return nil
+	}
+
+	response := NewErrorResponse(redigoErrors.Wrapf(
+		redigoErrors.ErrorInvalidUsage,
+		"'%s' requires a persistent connection",
+		strings.ToLower(arguments[0]),
+	))
+	return &response
+}
+
+// database returns the logical database selected by the session.
+func (session *Session) database() *redigo.Database {
+	return session.server.database.Database(session.db)
+}
+
+func HandleConnection(session *Session) {
+	defer session.connection.Close()
+	defer session.closeSubscriber()
+	defer session.closeMonitor()
+	defer session.unwatch()
+	defer session.flush()
+
+	for {
+		if session.reader.Buffered() == 0 {
+			if err := session.flush(); err != nil {
+				return
+			}
+		}
+
+		arguments, isInline, err := session.reader.ReadCommand()
+		session.writerMutex.Lock()
+		session.isInline = isInline
+		session.writerMutex.Unlock()
+
+		if errors.Is(err, resp.ErrorInvalidQuoting) {
+			if err := writeResponse(session, NewErrorResponse(
+				redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "protocol error: unbalanced quotes in request"),
+			)); err != nil {
+				return
+			}
+			continue
+		} else if errors.Is(err, resp.ErrorProtocol) {
+			writeResponse(session, NewErrorResponse(fmt.Errorf("protocol error: %v", err)))
+			return
+		} else if err != nil {
+			return
+		}
+
+		session.recordCommand(arguments[0])
+		response := HandleCommand(arguments, session)
+
+		if err := writeResponse(session, response); err != nil {
+			return
+		}
+
+		if session.shouldClose() {
+			return
+		}
+	}
+}
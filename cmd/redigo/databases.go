@@ -0,0 +1,93 @@
+package main
+
+import (
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/utils"
+
+	"github.com/samber/lo"
+)
+
+// parseDatabaseIndex parses the number of a logical database of the server.
+func parseDatabaseIndex(argument string, session *Session) (int, error) {
+	index, err := utils.FromStringToInt64(argument)
+	if err != nil {
+		return 0, redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "invalid DB index '%v'", argument)
+	}
+
+	if index < 0 || index >= int64(session.server.database.Databases()) {
+		return 0, redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "DB index is out of range")
+	}
+	return int(index), nil
+}
+
+func handleSelectCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if response := requirePersistentConnection(arguments, session); response != nil {
+		return *response
+	}
+
+	index, err := parseDatabaseIndex(arguments[1], session)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	session.informationMutex.Lock()
+	session.db = index
+	session.informationMutex.Unlock()
+
+	return NewSuccessResponse("OK")
+}
+
+func handleMoveCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	index, err := parseDatabaseIndex(arguments[2], session)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	moved, err := store.Move(arguments[1], index)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewIntegerResponse(lo.Ternary[int64](moved, 1, 0))
+}
+
+func handleSwapdbCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	indexes := make([]int, 2)
+	for position, argument := range arguments[1:] {
+		index, err := parseDatabaseIndex(argument, session)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		indexes[position] = index
+	}
+
+	if err := session.server.database.SwapDatabases(indexes[0], indexes[1]); err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse("OK")
+}
+
+func handleFlushdbCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	store.Flush()
+	return NewSuccessResponse("OK")
+}
+
+func handleFlushallCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	session.server.database.FlushAll()
+	return NewSuccessResponse("OK")
+}
+
+func init() {
+	lo.ForEach(
+		[]Command{
+			{Name: SELECT_COMMAND, Arity: 2, Usage: "SELECT {index}", Handler: handleSelectCommand},
+			{Name: MOVE_COMMAND, Arity: 3, Flags: []CommandFlag{WRITE_FLAG}, FirstKey: 1, LastKey: 1, KeyStep: 1, Usage: "MOVE {key} {db}", Handler: handleMoveCommand},
+			{Name: SWAPDB_COMMAND, Arity: 3, Flags: []CommandFlag{WRITE_FLAG, ADMIN_FLAG, NOMULTI_FLAG}, Usage: "SWAPDB {index1} {index2}", Handler: handleSwapdbCommand},
+			{Name: FLUSHDB_COMMAND, Arity: 1, Flags: []CommandFlag{WRITE_FLAG, ADMIN_FLAG}, Usage: "FLUSHDB", Handler: handleFlushdbCommand},
+			{Name: FLUSHALL_COMMAND, Arity: 1, Flags: []CommandFlag{WRITE_FLAG, ADMIN_FLAG, NOMULTI_FLAG}, Usage: "FLUSHALL", Handler: handleFlushallCommand},
+		},
+		func(command Command, _ int) {
+			RegisterCommand(command)
+		},
+	)
+}
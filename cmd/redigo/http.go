@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	redigoErrors "redigo/internal/redigo/errors"
+
+	"github.com/samber/lo"
+)
+
+type httpRoute func(request *http.Request) ([]string, error)
+
+// Time allowed to read the request headers, so that idle clients cannot hold connections forever.
+const HTTP_READ_HEADER_TIMEOUT = 10 * time.Second
+
+// Maps error codes to HTTP status codes, codes not listed answer 500.
+var httpErrorStatuses = map[redigoErrors.ErrorCode]int{
+	redigoErrors.CODE_NOT_FOUND:            http.StatusNotFound,
+	redigoErrors.CODE_EXPIRED:              http.StatusGone,
+	redigoErrors.CODE_EXISTS:               http.StatusConflict,
+	redigoErrors.CODE_WRONG_TYPE:           http.StatusBadRequest,
+	redigoErrors.CODE_USAGE:                http.StatusBadRequest,
+	redigoErrors.CODE_SYNTAX:               http.StatusBadRequest,
+	redigoErrors.CODE_UNKNOWN_COMMAND:      http.StatusBadRequest,
+	redigoErrors.CODE_UNSUPPORTED_PROTOCOL: http.StatusBadRequest,
+	redigoErrors.CODE_NO_AUTH:              http.StatusUnauthorized,
+	redigoErrors.CODE_WRONG_PASS:           http.StatusUnauthorized,
+	redigoErrors.CODE_NO_PERMISSION:        http.StatusForbidden,
+	redigoErrors.CODE_OUT_OF_MEMORY:        http.StatusInsufficientStorage,
+}
+
+func httpRoutes(maxValueSize int64) map[string]httpRoute {
+	// A MaxValueSize of 0 means unlimited, like on the RESP listeners.
+	body := func(request *http.Request) io.Reader {
+		if maxValueSize > 0 {
+			return http.MaxBytesReader(nil, request.Body, maxValueSize)
+		}
+		return request.Body
+	}
+
+	pathArguments := func(command string, names ...string) httpRoute {
+		return func(request *http.Request) ([]string, error) {
+			arguments := []string{command}
+			for _, name := range names {
+				arguments = append(arguments, request.PathValue(name))
+			}
+			return arguments, nil
+		}
+	}
+
+	return map[string]httpRoute{
+		"GET /keys/{key}":    pathArguments(GET_COMMAND, "key"),
+		"DELETE /keys/{key}": pathArguments(DELETE_COMMAND, "key"),
+		"PUT /keys/{key}": func(request *http.Request) ([]string, error) {
+			value, err := io.ReadAll(body(request))
+			if err != nil {
+				return nil, redigoErrors.Wrapf(redigoErrors.ErrorInvalidUsage, "failed to read value: %v", err)
+			}
+
+			arguments := []string{SET_COMMAND, request.PathValue("key"), string(value)}
+			if ttl := request.URL.Query().Get("ttl"); ttl != "" {
+				arguments = append(arguments, ttl)
+			}
+			return arguments, nil
+		},
+		"GET /keys/{key}/ttl": pathArguments(TTL_COMMAND, "key"),
+		"PUT /keys/{key}/ttl": func(request *http.Request) ([]string, error) {
+			seconds := request.URL.Query().Get("seconds")
+			if seconds == "" {
+				return nil, redigoErrors.Wrapf(redigoErrors.ErrorInvalidUsage, "missing 'seconds' query parameter")
+			}
+			return []string{EXPIRE_COMMAND, request.PathValue("key"), seconds}, nil
+		},
+		"GET /search/value/{value}":       pathArguments(SEARCH_VALUE_COMMAND, "value"),
+		"GET /search/prefix/{prefix}":     pathArguments(SEARCH_PREFIX_COMMAND, "prefix"),
+		"GET /search/suffix/{suffix}":     pathArguments(SEARCH_SUFFIX_COMMAND, "suffix"),
+		"GET /search/contains/{contains}": pathArguments(SEARCH_CONTAINS_COMMAND, "contains"),
+		"POST /save":                      pathArguments(SAVE_COMMAND),
+		"POST /bgsave":                    pathArguments(BGSAVE_COMMAND),
+		"POST /commands": func(request *http.Request) ([]string, error) {
+			arguments := []string{}
+			if err := json.NewDecoder(body(request)).Decode(&arguments); err != nil {
+				return nil, redigoErrors.Wrapf(redigoErrors.ErrorInvalidUsage, "body must be a JSON array of strings: %v", err)
+			}
+			return arguments, nil
+		},
+	}
+}
+
+// ListenHttp serves the JSON gateway, every route runs through HandleCommand
+// with a session authenticated from the Basic authorization header.
+func (server *Server) ListenHttp(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	for pattern, route := range httpRoutes(server.config.MaxValueSize) {
+		mux.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
+			server.serveHttpCommand(writer, request, route)
+		})
+	}
+
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: HTTP_READ_HEADER_TIMEOUT}
+
+	server.listenersMutex.Lock()
+	server.httpServers = append(server.httpServers, httpServer)
+	server.listenersMutex.Unlock()
+
+	go httpServer.Serve(listener)
+	return nil
+}
+
+func (server *Server) shutdownHttpServers(ctx context.Context) {
+	server.listenersMutex.Lock()
+	defer server.listenersMutex.Unlock()
+
+	for _, httpServer := range server.httpServers {
+		httpServer.Shutdown(ctx)
+	}
+}
+
+func (server *Server) serveHttpCommand(writer http.ResponseWriter, request *http.Request, route httpRoute) {
+	session := NewSession(server.nextSessionId(), nil, server)
+
+	if username, password, ok := request.BasicAuth(); ok {
+		if err := authenticate(session, username, password); err != nil {
+			writeHttpResponse(writer, NewErrorResponse(err))
+			return
+		}
+	}
+
+	arguments, err := route(request)
+	if err != nil {
+		writeHttpResponse(writer, NewErrorResponse(err))
+		return
+	}
+
+	writeHttpResponse(writer, HandleCommand(arguments, session))
+}
+
+func writeHttpResponse(writer http.ResponseWriter, response ClientResponse) {
+	status := http.StatusOK
+
+	if !response.Success {
+		status = lo.ValueOr(httpErrorStatuses, redigoErrors.CodeOf(response.Error), http.StatusInternalServerError)
+	}
+
+	if status == http.StatusUnauthorized {
+		writer.Header().Set("WWW-Authenticate", `Basic realm="redigo"`)
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(response)
+}
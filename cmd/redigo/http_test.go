@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHttpBodyLimit(t *testing.T) {
+	tests := []struct {
+		maxValueSize int64
+		accepted     bool
+	}{
+		{0, true},
+		{1024, true},
+		{4, false},
+	}
+
+	for _, test := range tests {
+		routes := httpRoutes(test.maxValueSize)
+
+		request := httptest.NewRequest("PUT", "/keys/a", strings.NewReader("hello"))
+		request.SetPathValue("key", "a")
+		arguments, err := routes["PUT /keys/{key}"](request)
+		if accepted := err == nil; accepted != test.accepted {
+			t.Errorf("PUT with MaxValueSize %d returned %v, expected accepted=%v", test.maxValueSize, err, test.accepted)
+		}
+		if test.accepted && !reflect.DeepEqual(arguments, []string{SET_COMMAND, "a", "hello"}) {
+			t.Errorf("PUT with MaxValueSize %d returned %q", test.maxValueSize, arguments)
+		}
+
+		request = httptest.NewRequest("POST", "/commands", strings.NewReader(`["GET", "a"]`))
+		arguments, err = routes["POST /commands"](request)
+		if test.maxValueSize == 0 && (err != nil || !reflect.DeepEqual(arguments, []string{"GET", "a"})) {
+			t.Errorf("POST /commands without limit returned %q, %v", arguments, err)
+		}
+	}
+}
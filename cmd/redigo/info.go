@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"redigo/internal/redigo"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+const (
+	INFO_DEFAULT_SECTION    = "default"    // Every section
+	INFO_ALL_SECTION        = "all"        // Every section
+	INFO_EVERYTHING_SECTION = "everything" // Every section
+)
+
+type infoSection struct {
+	name   string
+	fields func(server *Server) []lo.Entry[string, any]
+}
+
+var infoSections = []infoSection{
+	{name: "server", fields: serverInfo},
+	{name: "clients", fields: clientsInfo},
+	{name: "memory", fields: memoryInfo},
+	{name: "persistence", fields: persistenceInfo},
+	{name: "stats", fields: statsInfo},
+	{name: "keyspace", fields: keyspaceInfo},
+	{name: "indexes", fields: indexesInfo},
+}
+
+func serverInfo(server *Server) []lo.Entry[string, any] {
+	uptime := time.Since(server.startedAt)
+
+	return []lo.Entry[string, any]{
+		{Key: "redigo_version", Value: SERVER_VERSION},
+		{Key: "go_version", Value: runtime.Version()},
+		{Key: "os", Value: runtime.GOOS + " " + runtime.GOARCH},
+		{Key: "process_id", Value: os.Getpid()},
+		{Key: "tcp_port", Value: server.config.RedigoPort},
+		{Key: "uptime_in_seconds", Value: int64(uptime.Seconds())},
+		{Key: "uptime_in_days", Value: int64(uptime.Hours() / 24)},
+	}
+}
+
+func clientsInfo(server *Server) []lo.Entry[string, any] {
+	return []lo.Entry[string, any]{
+		{Key: "connected_clients", Value: len(server.Sessions())},
+		{Key: "maxclients", Value: server.config.MaxClients},
+	}
+}
+
+// memoryInfo reports the memory of the Go runtime, which includes the store,
+// the indexes and the buffers but also memory not yet garbage collected, and
+// the approximate memory of the keys and values, of the indexes, and of
+// everything the memory limit applies to.
+func memoryInfo(server *Server) []lo.Entry[string, any] {
+	var memoryStats runtime.MemStats
+	runtime.ReadMemStats(&memoryStats)
+
+	databaseStats := server.database.Stats()
+	usage := server.database.MemoryStats()
+	indexesMemory := usage.ValueIndex + usage.PrefixIndex + usage.SuffixIndex
+	config := server.database.Config()
+
+	return []lo.Entry[string, any]{
+		{Key: "used_memory", Value: memoryStats.HeapAlloc},
+		{Key: "used_memory_human", Value: humanBytes(memoryStats.HeapAlloc)},
+		{Key: "used_memory_sys", Value: memoryStats.Sys},
+		{Key: "used_memory_sys_human", Value: humanBytes(memoryStats.Sys)},
+		{Key: "gc_cycles", Value: memoryStats.NumGC},
+		{Key: "used_memory_dataset", Value: databaseStats.UsedMemory},
+		{Key: "used_memory_dataset_human", Value: humanBytes(uint64(databaseStats.UsedMemory))},
+		{Key: "used_memory_indexes", Value: indexesMemory},
+		{Key: "used_memory_indexes_human", Value: humanBytes(uint64(indexesMemory))},
+		{Key: "used_memory_counted", Value: databaseStats.CountedMemory},
+		{Key: "used_memory_counted_human", Value: humanBytes(uint64(databaseStats.CountedMemory))},
+		{Key: "maxmemory", Value: config.MaxMemory},
+		{Key: "maxmemory_human", Value: humanBytes(uint64(config.MaxMemory))},
+		{Key: "maxmemory_policy", Value: config.MaxMemoryPolicy},
+	}
+}
+
+func persistenceInfo(server *Server) []lo.Entry[string, any] {
+	stats := server.database.Stats()
+
+	return []lo.Entry[string, any]{
+		{Key: "persistence_enabled", Value: lo.Ternary(server.database.IsPersistent(), 1, 0)},
+		{Key: "aof_buffer_length", Value: stats.AofBufferLength},
+		{Key: "aof_last_flush_time", Value: unixTime(stats.LastAofFlush.Time)},
+		{Key: "aof_last_flush_status", Value: persistenceStatus(stats.LastAofFlush)},
+		{Key: "snapshot_last_save_time", Value: unixTime(stats.LastSnapshot.Time)},
+		{Key: "snapshot_last_save_status", Value: persistenceStatus(stats.LastSnapshot)},
+	}
+}
+
+func statsInfo(server *Server) []lo.Entry[string, any] {
+	databaseStats := server.database.Stats()
+
+	return []lo.Entry[string, any]{
+		{Key: "total_connections_received", Value: server.receivedConnections.Load()},
+		{Key: "total_commands_processed", Value: server.processedCommands.Load()},
+		{Key: "expired_keys", Value: databaseStats.ExpiredKeys},
+		{Key: "evicted_keys", Value: databaseStats.EvictedKeys},
+		{Key: "pubsub_channels", Value: len(server.pubsub.Channels(""))},
+		{Key: "pubsub_patterns", Value: server.pubsub.NumPatterns()},
+	}
+}
+
+// keyspaceInfo lists the databases holding keys.
+func keyspaceInfo(server *Server) []lo.Entry[string, any] {
+	stats := server.database.Stats()
+
+	return lo.FilterMap(stats.Keyspaces, func(keyspace redigo.KeyspaceStats, index int) (lo.Entry[string, any], bool) {
+		return lo.Entry[string, any]{
+			Key:   fmt.Sprintf("db%d", index),
+			Value: fmt.Sprintf("keys=%d,expires=%d", keyspace.Keys, keyspace.ExpiringKeys),
+		}, keyspace.Keys > 0
+	})
+}
+
+func indexesInfo(server *Server) []lo.Entry[string, any] {
+	stats := server.database.Stats()
+
+	return []lo.Entry[string, any]{
+		{Key: "value_index_entries", Value: stats.ValueIndexEntries},
+		{Key: "prefix_index_entries", Value: stats.PrefixIndexEntries},
+		{Key: "suffix_index_entries", Value: stats.SuffixIndexEntries},
+	}
+}
+
+func persistenceStatus(status redigo.PersistenceStatus) string {
+	return lo.Ternary(status.Error != nil, "err", "ok")
+}
+
+// unixTime returns 0 for the zero time, when an operation never ran.
+func unixTime(value time.Time) int64 {
+	return lo.Ternary(value.IsZero(), 0, value.Unix())
+}
+
+func humanBytes(bytes uint64) string {
+	units := []string{"B", "K", "M", "G", "T"}
+
+	size := float64(bytes)
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+
+	return fmt.Sprintf("%.2f%s", size, units[unit])
+}
+
+// handleInfoCommand reports the requested sections, or all of them, as
+// "# Section" headers followed by "field:value" lines.
+func handleInfoCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	requested := lo.Map(arguments[1:], func(section string, _ int) string { return strings.ToLower(section) })
+	showAll := len(requested) == 0 || lo.Some(requested, []string{INFO_DEFAULT_SECTION, INFO_ALL_SECTION, INFO_EVERYTHING_SECTION})
+
+	sections := lo.Filter(infoSections, func(section infoSection, _ int) bool {
+		return showAll || lo.Contains(requested, section.name)
+	})
+
+	blocks := lo.Map(sections, func(section infoSection, _ int) []string {
+		fields := lo.Map(section.fields(session.server), func(field lo.Entry[string, any], _ int) string {
+			return fmt.Sprintf("%s:%v", field.Key, field.Value)
+		})
+		return append([]string{"# " + strings.ToUpper(section.name[:1]) + section.name[1:]}, fields...)
+	})
+
+	lines := lo.FlatMap(blocks, func(block []string, index int) []string {
+		return lo.Ternary(index < len(blocks)-1, append(block, ""), block)
+	})
+
+	return NewBulkResponse(strings.Join(lines, "\n")).
+		WithReply(resp.NewBulkString(strings.Join(lines, "\r\n") + "\r\n"))
+}
+
+func init() {
+	RegisterCommand(Command{
+		Name:    INFO_COMMAND,
+		Arity:   -1,
+		Flags:   []CommandFlag{NOMULTI_FLAG},
+		Usage:   "INFO [section ...]",
+		Handler: handleInfoCommand,
+	})
+}
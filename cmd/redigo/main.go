@@ -1,48 +1,24 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
-	"net"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"redigo/envs"
+	"redigo/internal/acl"
+	"redigo/internal/pubsub"
 	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
 	"redigo/pkg/utils"
-)
-
-type ClientResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Error   error  `json:"error,omitempty"`
-}
-
-func NewSuccessResponse(message string) ClientResponse {
-	return ClientResponse{
-		Success: true,
-		Message: message,
-		Error:   nil,
-	}
-}
-
-func NewErrorResponse(err error) ClientResponse {
-	return ClientResponse{
-		Success: false,
-		Message: fmt.Sprintf("Error: %v", err),
-		Error:   err,
-	}
-}
 
-func NewUsageErrorResponse(usage string) ClientResponse {
-	return ClientResponse{
-		Success: false,
-		Message: usage,
-		Error:   fmt.Errorf("invalid usage"),
-	}
-}
-
-func (response ClientResponse) ToString() string {
-	return response.Message + "\n"
-}
+	"github.com/samber/lo"
+)
 
 const (
 	SET_COMMAND             = "SET"            // Store key-value pair
@@ -56,37 +32,40 @@ const (
 	SEARCH_PREFIX_COMMAND   = "SEARCHPREFIX"   // Find keys starting with prefix
 	SEARCH_SUFFIX_COMMAND   = "SEARCHSUFFIX"   // Find keys ending with suffix
 	SEARCH_CONTAINS_COMMAND = "SEARCHCONTAINS" // Find keys containing substring
+	PING_COMMAND            = "PING"           // Check that the server is alive
+	HELLO_COMMAND           = "HELLO"          // Negotiate the RESP protocol version
+	SHUTDOWN_COMMAND        = "SHUTDOWN"       // Flush, optionally save, and stop the server
+	CLIENT_COMMAND          = "CLIENT"         // Inspect and manage client connections
+	AUTH_COMMAND            = "AUTH"           // Authenticate the connection as an ACL user
+	ACL_COMMAND             = "ACL"            // Manage ACL users
+	COMMAND_COMMAND         = "COMMAND"        // Describe the commands the server supports
+	SUBSCRIBE_COMMAND       = "SUBSCRIBE"      // Receive the messages published to channels
+	UNSUBSCRIBE_COMMAND     = "UNSUBSCRIBE"    // Stop receiving the messages of channels
+	PSUBSCRIBE_COMMAND      = "PSUBSCRIBE"     // Receive the messages published to channels matching glob patterns
+	PUNSUBSCRIBE_COMMAND    = "PUNSUBSCRIBE"   // Stop receiving the messages of patterns
+	PUBLISH_COMMAND         = "PUBLISH"        // Send a message to the subscribers of a channel
+	PUBSUB_COMMAND          = "PUBSUB"         // Inspect the pub/sub channels and subscribers
+	MULTI_COMMAND           = "MULTI"          // Start queueing commands for a transaction
+	EXEC_COMMAND            = "EXEC"           // Run the queued commands atomically
+	DISCARD_COMMAND         = "DISCARD"        // Drop the queued commands
+	WATCH_COMMAND           = "WATCH"          // Abort the next transaction if keys change
+	UNWATCH_COMMAND         = "UNWATCH"        // Forget the watched keys
+	MONITOR_COMMAND         = "MONITOR"        // Stream every command the server processes
+	SLOWLOG_COMMAND         = "SLOWLOG"        // Inspect the commands slower than the threshold
+	LATENCY_COMMAND         = "LATENCY"        // Inspect the latency histograms of commands
+	INFO_COMMAND            = "INFO"           // Report server, memory, persistence, keyspace and index statistics
+	CONFIG_COMMAND          = "CONFIG"         // Read and change the configuration at runtime
+	MEMORY_COMMAND          = "MEMORY"         // Estimate the memory used by keys and by the database
+	SELECT_COMMAND          = "SELECT"         // Select the logical database of the connection
+	MOVE_COMMAND            = "MOVE"           // Move a key to another logical database
+	SWAPDB_COMMAND          = "SWAPDB"         // Swap the keys of two logical databases
+	FLUSHDB_COMMAND         = "FLUSHDB"        // Remove every key of the selected database
+	FLUSHALL_COMMAND        = "FLUSHALL"       // Remove every key of every database
 )
 
-func writeResponse(conn net.Conn, response ClientResponse) {
-	stringResponse := response.ToString()
-	if conn != nil {
-		conn.Write([]byte(stringResponse))
-	} else {
-		fmt.Print(stringResponse)
-	}
-}
-
-func HandleConnection(connection net.Conn, store *redigo.RedigoDB) {
-	defer connection.Close()
-	buffer := make([]byte, 1024)
+const SERVER_VERSION = "1.0.0"
 
-	for {
-		numberOfBytesRead, err := connection.Read(buffer)
-		if err != nil {
-			return
-		}
-
-		rawCommand := strings.TrimSpace(string(buffer[:numberOfBytesRead]))
-		cookedCommand := strings.Fields(rawCommand)
-
-		response := HandleCommand(cookedCommand, store)
-
-		writeResponse(connection, response)
-	}
-}
-
-func handleSetCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
+func handleSetCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
 	numberOfArguments := len(arguments)
 	if numberOfArguments < 3 || numberOfArguments > 4 {
 		return NewUsageErrorResponse("Usage: SET {key} {value} [ttl]")
@@ -96,84 +75,70 @@ func handleSetCommand(arguments []string, store *redigo.RedigoDB) ClientResponse
 	if numberOfArguments == 4 {
 		var err error
 		if ttl, err = utils.FromStringToInt64(arguments[3]); err != nil {
-			return NewErrorResponse(fmt.Errorf("invalid TTL value: %v", err))
+			return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "invalid TTL value '%v'", arguments[3]))
 		}
 	} else {
-		config := envs.Gets()
-		ttl = config.DefaultTTL
+		ttl = store.DefaultTtl()
 	}
 
 	if err := store.Set(arguments[1], arguments[2], ttl); err != nil {
-		return NewErrorResponse(fmt.Errorf("failed to set value: %v", err))
+		return NewErrorResponse(fmt.Errorf("failed to set value: %w", err))
 	}
 	return NewSuccessResponse("OK")
 }
 
-func handleGetCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
-	if len(arguments) != 2 {
-		return NewUsageErrorResponse("Usage: GET {key}")
-	}
-
+func handleGetCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
 	value, err := store.Get(arguments[1])
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("failed to get value: %v", err))
+		return NewNilResponse(fmt.Errorf("failed to get value: %w", err))
 	}
-	return NewSuccessResponse(fmt.Sprintf("%v", value))
+	return NewBulkResponse(utils.ValueToString(value))
 }
 
-func handleDeleteCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
-	if len(arguments) != 2 {
-		return NewUsageErrorResponse("Usage: DELETE {key}")
-	}
-
+func handleDeleteCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
 	if hasDeleted := store.Delete(arguments[1]); hasDeleted {
-		return NewSuccessResponse("1")
+		return NewIntegerResponse(1)
 	}
-	return NewSuccessResponse("0")
+	return NewIntegerResponse(0)
 }
 
-func handleTtlCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
-	if len(arguments) != 2 {
-		return NewUsageErrorResponse("Usage: TTL {key}")
-	}
-
+func handleTtlCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
 	requestedKey := arguments[1]
 	ttl, exists := store.GetTtl(requestedKey)
 	if !exists {
-		return NewSuccessResponse(fmt.Sprintf("Key : %v doesn't exists.", requestedKey))
+		return NewSuccessResponse(fmt.Sprintf("Key : %v doesn't exists.", requestedKey)).
+			WithReply(resp.NewInteger(-2))
 	} else if ttl == 0 {
-		return NewSuccessResponse(fmt.Sprintf("No expiration for : %v.", requestedKey))
+		return NewSuccessResponse(fmt.Sprintf("No expiration for : %v.", requestedKey)).
+			WithReply(resp.NewInteger(-1))
 	}
-	return NewSuccessResponse(fmt.Sprintf("%d", ttl))
+	return NewIntegerResponse(ttl)
 }
 
-func handleExpireCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
-	if len(arguments) != 3 {
-		return NewUsageErrorResponse("Usage: EXPIRE {key} seconds")
-	}
-
+func handleExpireCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
 	requestedKey := arguments[1]
 	seconds, err := utils.FromStringToInt64(arguments[2])
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("invalid seconds value: %v", err))
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "invalid seconds value '%v'", arguments[2]))
 	}
 
 	if success := store.SetExpiry(requestedKey, seconds); success {
-		return NewSuccessResponse("OK")
+		return NewSuccessResponse("OK").WithReply(resp.NewInteger(1))
 	}
-	return NewSuccessResponse(fmt.Sprintf("Key : %v doesn't exists.", requestedKey))
+	return NewSuccessResponse(fmt.Sprintf("Key : %v doesn't exists.", requestedKey)).
+		WithReply(resp.NewInteger(0))
 }
 
-func handleSaveCommand(store *redigo.RedigoDB) ClientResponse {
-	if err := store.ForceSave(); err != nil {
-		return NewErrorResponse(fmt.Errorf("failed to save database: %v", err))
+func handleSaveCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if err := session.server.database.ForceSave(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to save database: %w", err))
 	}
-	return NewSuccessResponse("Database saved successfully")
+	return NewSuccessResponse("Database saved successfully").WithReply(resp.NewSimpleString("OK"))
 }
 
-func handleBgsaveCommand(store *redigo.RedigoDB) ClientResponse {
+func handleBgsaveCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
 	go func() {
-		if err := store.UpdateSnapshot(); err != nil {
+		if err := session.server.database.UpdateSnapshot(); err != nil {
 			fmt.Printf("Background snapshot failed: %v\n", err)
 		} else {
 			fmt.Println("Background saving completed successfully")
@@ -182,87 +147,193 @@ func handleBgsaveCommand(store *redigo.RedigoDB) ClientResponse {
 	return NewSuccessResponse("Background saving started")
 }
 
-func handleSearchValueCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
-	if len(arguments) != 2 {
-		return NewUsageErrorResponse("Usage: SEARCHVALUE {value}")
+// newSearchResponse lists the found keys the session user is allowed to access,
+// the SEARCH commands having no key arguments the ACL cannot check beforehand.
+func newSearchResponse(keys []string, session *Session) ClientResponse {
+	keys = session.server.acl.AccessibleKeys(session.Username(), keys)
+
+	if len(keys) == 0 {
+		return NewSuccessResponse("No keys found").WithReply(resp.NewArray(nil))
 	}
+	return NewSuccessResponse(fmt.Sprintf("Found keys: %v", keys)).WithReply(resp.NewBulkStringArray(keys))
+}
 
+func handleSearchValueCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
 	keys := store.SearchByValue(arguments[1])
-	if keys == nil {
-		return NewSuccessResponse("No keys found")
-	}
-	return NewSuccessResponse(fmt.Sprintf("Found keys: %v", keys))
+	return newSearchResponse(keys, session)
+}
+
+func handleSearchPrefixCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	keys := store.SearchByKeyPrefix(arguments[1])
+	return newSearchResponse(keys, session)
+}
+
+func handleSearchSuffixCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	keys := store.SearchByKeySuffix(arguments[1])
+	return newSearchResponse(keys, session)
+}
+
+func handleSearchContainsCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	keys := store.SearchByKeyContains(arguments[1])
+	return newSearchResponse(keys, session)
 }
 
-func handleSearchPrefixCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
-	if len(arguments) != 2 {
-		return NewUsageErrorResponse("Usage: SEARCHPREFIX {prefix}")
+func handlePingCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if session.isSubscribed() && session.writer.Protocol == resp.RESP2 && len(arguments) <= 2 {
+		message := lo.NthOr(arguments, 1, "")
+		return NewSuccessResponse("pong " + message).
+			WithReply(resp.NewBulkStringArray([]string{"pong", message}))
 	}
 
-	keys := store.SearchByKeyPrefix(arguments[1])
-	if keys == nil {
-		return NewSuccessResponse("No keys found")
+	switch len(arguments) {
+	case 1:
+		return NewSuccessResponse("PONG")
+	case 2:
+		return NewBulkResponse(arguments[1])
+	default:
+		return NewUsageErrorResponse("Usage: PING [message]")
 	}
-	return NewSuccessResponse(fmt.Sprintf("Found keys: %v", keys))
 }
 
-func handleSearchSuffixCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
-	if len(arguments) != 2 {
-		return NewUsageErrorResponse("Usage: SEARCHSUFFIX {suffix}")
+func handleHelloCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	usage := "Usage: HELLO [protover [AUTH {username} {password}] [SETNAME {clientname}]]"
+	protocol := int64(session.writer.Protocol)
+
+	if len(arguments) >= 2 {
+		var err error
+		protocol, err = utils.FromStringToInt64(arguments[1])
+		if err != nil || (protocol != resp.RESP2 && protocol != resp.RESP3) {
+			return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnsupportedProtocol, "unsupported protocol version '%v'", arguments[1]))
+		}
 	}
 
-	keys := store.SearchByKeySuffix(arguments[1])
-	if keys == nil {
-		return NewSuccessResponse("No keys found")
+	clientName := ""
+	for index := 2; index < len(arguments); index++ {
+		switch option := strings.ToUpper(arguments[index]); {
+		case option == "AUTH" && index+2 < len(arguments):
+			if err := authenticate(session, arguments[index+1], arguments[index+2]); err != nil {
+				return NewErrorResponse(err)
+			}
+			index += 2
+		case option == "SETNAME" && index+1 < len(arguments):
+			clientName = arguments[index+1]
+			index++
+		default:
+			return NewUsageErrorResponse(usage)
+		}
 	}
-	return NewSuccessResponse(fmt.Sprintf("Found keys: %v", keys))
+
+	if session.Username() == "" {
+		return NewErrorResponse(redigoErrors.ErrorAuthenticationRequired)
+	}
+
+	if clientName != "" {
+		session.SetName(clientName)
+	}
+	session.writer.Protocol = int(protocol)
+
+	serverInformation := []lo.Entry[string, resp.Value]{
+		{Key: "server", Value: resp.NewBulkString("redigo")},
+		{Key: "version", Value: resp.NewBulkString(SERVER_VERSION)},
+		{Key: "proto", Value: resp.NewInteger(int64(session.writer.Protocol))},
+		{Key: "mode", Value: resp.NewBulkString("standalone")},
+		{Key: "role", Value: resp.NewBulkString("master")},
+		{Key: "modules", Value: resp.NewArray(nil)},
+	}
+
+	pairs := lo.FlatMap(
+		serverInformation,
+		func(entry lo.Entry[string, resp.Value], _ int) []resp.Value {
+			return []resp.Value{resp.NewBulkString(entry.Key), entry.Value}
+		},
+	)
+
+	return NewSuccessResponse(fmt.Sprintf("redigo %s (protocol %d)", SERVER_VERSION, session.writer.Protocol)).
+		WithReply(resp.NewMap(pairs...))
 }
 
-func handleSearchContainsCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
-	if len(arguments) != 2 {
-		return NewUsageErrorResponse("Usage: SEARCHCONTAINS {substring}")
+func handleShutdownCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if len(arguments) > 2 {
+		return NewUsageErrorResponse("Usage: SHUTDOWN [SAVE|NOSAVE]")
 	}
 
-	keys := store.SearchByKeyContains(arguments[1])
-	if len(keys) == 0 {
-		return NewSuccessResponse("No keys found")
+	save := session.server.config.SaveOnShutdown
+	if len(arguments) == 2 {
+		switch strings.ToUpper(arguments[1]) {
+		case "SAVE":
+			save = true
+		case "NOSAVE":
+			save = false
+		default:
+			return NewUsageErrorResponse("Usage: SHUTDOWN [SAVE|NOSAVE]")
+		}
 	}
-	return NewSuccessResponse(fmt.Sprintf("Found keys: %v", keys))
+
+	session.server.RequestShutdown(save)
+	return NewSuccessResponse("OK")
 }
 
-func HandleCommand(arguments []string, store *redigo.RedigoDB) ClientResponse {
+// HandleCommand looks the command up in the registry, checks its arity and the
+// ACL rules of the session user, then runs its handler on the selected database.
+func HandleCommand(arguments []string, session *Session) ClientResponse {
 	if len(arguments) == 0 {
 		return NewUsageErrorResponse("Invalid command!")
 	}
 
-	requestedCommand := strings.ToUpper(arguments[0])
-
-	switch requestedCommand {
-	case SET_COMMAND:
-		return handleSetCommand(arguments, store)
-	case GET_COMMAND:
-		return handleGetCommand(arguments, store)
-	case DELETE_COMMAND:
-		return handleDeleteCommand(arguments, store)
-	case TTL_COMMAND:
-		return handleTtlCommand(arguments, store)
-	case EXPIRE_COMMAND:
-		return handleExpireCommand(arguments, store)
-	case SAVE_COMMAND:
-		return handleSaveCommand(store)
-	case BGSAVE_COMMAND:
-		return handleBgsaveCommand(store)
-	case SEARCH_VALUE_COMMAND:
-		return handleSearchValueCommand(arguments, store)
-	case SEARCH_PREFIX_COMMAND:
-		return handleSearchPrefixCommand(arguments, store)
-	case SEARCH_SUFFIX_COMMAND:
-		return handleSearchSuffixCommand(arguments, store)
-	case SEARCH_CONTAINS_COMMAND:
-		return handleSearchContainsCommand(arguments, store)
-	default:
-		return NewErrorResponse(fmt.Errorf("unknown command '%v'", arguments[0]))
+	command, exists := LookupCommand(arguments[0])
+	if !exists {
+		return session.failTransaction(NewErrorResponse(
+			redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown command '%v'", arguments[0]),
+		))
+	}
+
+	if err := authorizeCommand(command, arguments, session); err != nil {
+		return session.failTransaction(NewErrorResponse(err))
 	}
+
+	if session.isSubscribed() && session.writer.Protocol == resp.RESP2 && !command.HasFlag(PUBSUB_FLAG) {
+		return NewErrorResponse(redigoErrors.Wrapf(
+			redigoErrors.ErrorInvalidUsage,
+			"can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING are allowed in this context",
+			strings.ToLower(command.Name),
+		))
+	}
+
+	if !command.AcceptsArity(len(arguments)) {
+		return session.failTransaction(NewUsageErrorResponse("Usage: " + command.Usage))
+	}
+
+	session.server.feedMonitors(arguments, session)
+
+	if session.transaction != nil && command.Name != EXEC_COMMAND && command.Name != DISCARD_COMMAND {
+		return queueCommand(command, arguments, session)
+	}
+
+	return runCommand(command, arguments, session, session.database())
+}
+
+func init() {
+	lo.ForEach(
+		[]Command{
+			{Name: SET_COMMAND, Arity: -3, Flags: []CommandFlag{WRITE_FLAG}, FirstKey: 1, LastKey: 1, KeyStep: 1, Usage: "SET {key} {value} [ttl]", Handler: handleSetCommand},
+			{Name: GET_COMMAND, Arity: 2, Flags: []CommandFlag{READONLY_FLAG}, FirstKey: 1, LastKey: 1, KeyStep: 1, Usage: "GET {key}", Handler: handleGetCommand},
+			{Name: DELETE_COMMAND, Arity: 2, Flags: []CommandFlag{WRITE_FLAG}, FirstKey: 1, LastKey: 1, KeyStep: 1, Usage: "DELETE {key}", Handler: handleDeleteCommand},
+			{Name: TTL_COMMAND, Arity: 2, Flags: []CommandFlag{READONLY_FLAG}, FirstKey: 1, LastKey: 1, KeyStep: 1, Usage: "TTL {key}", Handler: handleTtlCommand},
+			{Name: EXPIRE_COMMAND, Arity: 3, Flags: []CommandFlag{WRITE_FLAG}, FirstKey: 1, LastKey: 1, KeyStep: 1, Usage: "EXPIRE {key} seconds", Handler: handleExpireCommand},
+			{Name: SAVE_COMMAND, Arity: 1, Flags: []CommandFlag{ADMIN_FLAG, NOMULTI_FLAG}, Usage: "SAVE", Handler: handleSaveCommand},
+			{Name: BGSAVE_COMMAND, Arity: 1, Flags: []CommandFlag{ADMIN_FLAG}, Usage: "BGSAVE", Handler: handleBgsaveCommand},
+			{Name: SEARCH_VALUE_COMMAND, Arity: 2, Flags: []CommandFlag{READONLY_FLAG}, Usage: "SEARCHVALUE {value}", Handler: handleSearchValueCommand},
+			{Name: SEARCH_PREFIX_COMMAND, Arity: 2, Flags: []CommandFlag{READONLY_FLAG}, Usage: "SEARCHPREFIX {prefix}", Handler: handleSearchPrefixCommand},
+			{Name: SEARCH_SUFFIX_COMMAND, Arity: 2, Flags: []CommandFlag{READONLY_FLAG}, Usage: "SEARCHSUFFIX {suffix}", Handler: handleSearchSuffixCommand},
+			{Name: SEARCH_CONTAINS_COMMAND, Arity: 2, Flags: []CommandFlag{READONLY_FLAG}, Usage: "SEARCHCONTAINS {substring}", Handler: handleSearchContainsCommand},
+			{Name: PING_COMMAND, Arity: -1, Flags: []CommandFlag{PUBSUB_FLAG}, Usage: "PING [message]", Handler: handlePingCommand},
+			{Name: HELLO_COMMAND, Arity: -1, Flags: []CommandFlag{NOAUTH_FLAG, NOMULTI_FLAG}, Usage: "HELLO [protover [AUTH {username} {password}] [SETNAME {clientname}]]", Handler: handleHelloCommand},
+			{Name: SHUTDOWN_COMMAND, Arity: -1, Flags: []CommandFlag{ADMIN_FLAG, NOMULTI_FLAG}, Usage: "SHUTDOWN [SAVE|NOSAVE]", Handler: handleShutdownCommand},
+		},
+		func(command Command, _ int) {
+			RegisterCommand(command)
+		},
+	)
 }
 
 func main() {
@@ -270,7 +341,31 @@ func main() {
 	config := envs.Gets()
 	port := config.RedigoPort
 
-	database, err := redigo.InitializeRedigo()
+	dataDirPath, err := utils.GetRedigoFullPath(config.RedigoRootDirPath)
+	if err != nil {
+		writeResponse(nil, NewErrorResponse(fmt.Errorf("failed to create data directory: %v", err)))
+		return
+	}
+
+	keyspaceEvents, err := pubsub.ParseKeyspaceEvents(config.NotifyKeyspaceEvents)
+	if err != nil {
+		writeResponse(nil, NewErrorResponse(fmt.Errorf("invalid keyspace events: %v", err)))
+		return
+	}
+
+	broker := pubsub.NewBroker(config.PubsubBufferSize)
+
+	database, err := redigo.Open(
+		redigo.WithDataDir(dataDirPath),
+		redigo.WithSnapshotInterval(config.SnapshotSaveInterval),
+		redigo.WithFlushBufferInterval(config.FlushBufferInterval),
+		redigo.WithExpirationInterval(config.DataExpirationInterval),
+		redigo.WithDefaultTTL(time.Duration(config.DefaultTTL)*time.Second),
+		redigo.WithKeyspaceNotifier(broker.KeyspaceNotifier(keyspaceEvents)),
+		redigo.WithMaxMemory(config.MaxMemory),
+		redigo.WithMaxMemoryPolicy(redigo.EvictionPolicy(config.MaxMemoryPolicy)),
+		redigo.WithDatabases(config.Databases),
+	)
 	if err != nil {
 		writeResponse(
 			nil,
@@ -279,23 +374,96 @@ func main() {
 		return
 	}
 
-	defer database.CloseAof()
-	writeResponse(
-		nil,
-		NewSuccessResponse(fmt.Sprintf("Redigo server started on port %s\n", port)),
-	)
-
-	listener, err := net.Listen("tcp", ":"+port)
+	aclManager, err := acl.NewManager(filepath.Join(dataDirPath, utils.ACL_FILENAME))
 	if err != nil {
-		panic(err)
+		writeResponse(nil, NewErrorResponse(fmt.Errorf("failed to load ACL users: %v", err)))
+		return
+	}
+	if err := aclManager.SetUserDefinitions(config.AclUsers); err != nil {
+		writeResponse(nil, NewErrorResponse(fmt.Errorf("failed to load ACL users: %v", err)))
+		return
+	}
+
+	var tlsConfig *tls.Config
+	if config.TlsPort != "" {
+		if tlsConfig, err = loadTlsConfig(config); err != nil {
+			writeResponse(nil, NewErrorResponse(fmt.Errorf("failed to configure TLS: %v", err)))
+			return
+		}
+	}
+
+	var unixSocketPermissions uint64
+	if config.UnixSocketPath != "" {
+		if unixSocketPermissions, err = strconv.ParseUint(config.UnixSocketPermissions, 8, 32); err != nil {
+			writeResponse(nil, NewErrorResponse(fmt.Errorf("invalid Unix socket permissions: %v", err)))
+			return
+		}
+	}
+
+	server := NewServer(database, config, aclManager, broker)
+
+	if port != "" && port != "0" {
+		if err := server.Listen("tcp", ":"+port); err != nil {
+			panic(err)
+		}
+
+		writeResponse(
+			nil,
+			NewSuccessResponse(fmt.Sprintf("Redigo server started on port %s\n", port)),
+		)
+	}
+
+	if tlsConfig != nil {
+		if err := server.ListenTLS(":"+config.TlsPort, tlsConfig); err != nil {
+			panic(err)
+		}
+
+		writeResponse(
+			nil,
+			NewSuccessResponse(fmt.Sprintf("Redigo TLS server started on port %s\n", config.TlsPort)),
+		)
 	}
-	defer listener.Close()
 
-	for {
-		conn, err := listener.Accept()
-		if err != nil {
-			continue
+	if config.UnixSocketPath != "" {
+		if err := server.ListenUnix(config.UnixSocketPath, os.FileMode(unixSocketPermissions)); err != nil {
+			panic(err)
 		}
-		go HandleConnection(conn, database)
+
+		writeResponse(
+			nil,
+			NewSuccessResponse(fmt.Sprintf("Redigo server listening on Unix socket %s\n", config.UnixSocketPath)),
+		)
 	}
+
+	if config.HttpPort != "" {
+		if err := server.ListenHttp(":" + config.HttpPort); err != nil {
+			panic(err)
+		}
+
+		writeResponse(
+			nil,
+			NewSuccessResponse(fmt.Sprintf("Redigo HTTP gateway started on port %s\n", config.HttpPort)),
+		)
+	}
+
+	if config.MetricsPort != "" {
+		if err := server.ListenMetrics(":" + config.MetricsPort); err != nil {
+			panic(err)
+		}
+
+		writeResponse(
+			nil,
+			NewSuccessResponse(fmt.Sprintf("Redigo metrics endpoint started on port %s\n", config.MetricsPort)),
+		)
+	}
+
+	go server.StartIdleClientsListener()
+
+	save := server.WaitForShutdown()
+	if err := server.Shutdown(save); err != nil {
+		writeResponse(nil, NewErrorResponse(fmt.Errorf("failed to shut down cleanly: %v", err)))
+		os.Exit(1)
+	}
+
+	writeResponse(nil, NewSuccessResponse("Redigo server stopped"))
 }
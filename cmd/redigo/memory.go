@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+const (
+	MEMORY_USAGE_SUBCOMMAND = "USAGE" // Estimate the memory used by a key
+	MEMORY_STATS_SUBCOMMAND = "STATS" // Break down the memory used by the database
+)
+
+func handleMemoryUsage(arguments []string, session *Session) ClientResponse {
+	if len(arguments) != 3 {
+		return NewUsageErrorResponse("Usage: MEMORY USAGE {key}")
+	}
+
+	usage, exists := session.database().MemoryUsage(arguments[2])
+	if !exists {
+		return NewNilResponse(redigoErrors.Wrapf(redigoErrors.ErrorKeyNotFound, "key '%v' not found", arguments[2]))
+	}
+	return NewIntegerResponse(usage)
+}
+
+func handleMemoryStats(arguments []string, session *Session) ClientResponse {
+	if len(arguments) != 2 {
+		return NewUsageErrorResponse("Usage: MEMORY STATS")
+	}
+
+	var memoryStats runtime.MemStats
+	runtime.ReadMemStats(&memoryStats)
+
+	databaseStats := session.server.database.Stats()
+	usage := session.server.database.MemoryStats()
+	config := session.server.database.Config()
+
+	fields := []lo.Entry[string, int64]{
+		{Key: "store.bytes", Value: usage.Store},
+		{Key: "expires.bytes", Value: usage.Expirations},
+		{Key: "index.value.bytes", Value: usage.ValueIndex},
+		{Key: "index.prefix.bytes", Value: usage.PrefixIndex},
+		{Key: "index.suffix.bytes", Value: usage.SuffixIndex},
+		{Key: "aof.buffer.bytes", Value: usage.AofBuffer},
+		{Key: "total.bytes", Value: usage.Total()},
+		{Key: "maxmemory", Value: config.MaxMemory},
+		{Key: "maxmemory.counted.bytes", Value: usage.Counted()},
+		{Key: "keys.count", Value: int64(databaseStats.Keys)},
+		{Key: "allocator.allocated", Value: int64(memoryStats.HeapAlloc)},
+	}
+
+	lines := lo.Map(fields, func(field lo.Entry[string, int64], _ int) string {
+		return fmt.Sprintf("%s %d", field.Key, field.Value)
+	})
+	pairs := lo.FlatMap(fields, func(field lo.Entry[string, int64], _ int) []resp.Value {
+		return []resp.Value{resp.NewBulkString(field.Key), resp.NewInteger(field.Value)}
+	})
+
+	return NewSuccessResponse(strings.Join(lines, "\n")).WithReply(resp.NewMap(pairs...))
+}
+
+func handleMemoryCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	switch strings.ToUpper(arguments[1]) {
+	case MEMORY_USAGE_SUBCOMMAND:
+		return handleMemoryUsage(arguments, session)
+	case MEMORY_STATS_SUBCOMMAND:
+		return handleMemoryStats(arguments, session)
+	default:
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown MEMORY subcommand '%v'", arguments[1]))
+	}
+}
+
+func init() {
+	RegisterCommand(Command{
+		Name:     MEMORY_COMMAND,
+		Arity:    -2,
+		Flags:    []CommandFlag{READONLY_FLAG, NOMULTI_FLAG},
+		FirstKey: 2,
+		LastKey:  2,
+		KeyStep:  1,
+		Usage:    "MEMORY USAGE {key}|STATS",
+		Handler:  handleMemoryCommand,
+	})
+}
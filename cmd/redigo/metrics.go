@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"redigo/internal/stats"
+
+	"github.com/samber/lo"
+)
+
+const (
+	COUNTER_METRIC = "counter"
+	GAUGE_METRIC   = "gauge"
+	SUMMARY_METRIC = "summary"
+)
+
+const METRICS_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+
+type metricSample struct {
+	suffix string // Appended to the metric name, "_sum" and "_count" for summaries
+	labels []lo.Entry[string, string]
+	value  float64
+}
+
+type metric struct {
+	name    string
+	help    string
+	kind    string
+	samples []metricSample
+}
+
+// writeTo writes metric in the Prometheus text exposition format.
+func (metric metric) writeTo(writer io.Writer) {
+	fmt.Fprintf(writer, "# HELP %s %s\n", metric.name, metric.help)
+	fmt.Fprintf(writer, "# TYPE %s %s\n", metric.name, metric.kind)
+
+	for _, sample := range metric.samples {
+		labels := lo.Map(sample.labels, func(label lo.Entry[string, string], _ int) string {
+			return fmt.Sprintf("%s=%q", label.Key, label.Value)
+		})
+
+		fmt.Fprintf(
+			writer,
+			"%s%s%s %v\n",
+			metric.name,
+			sample.suffix,
+			lo.Ternary(len(labels) > 0, "{"+strings.Join(labels, ",")+"}", ""),
+			sample.value,
+		)
+	}
+}
+
+func newSingleMetric(name, help, kind string, value float64) metric {
+	return metric{name: name, help: help, kind: kind, samples: []metricSample{{value: value}}}
+}
+
+func (server *Server) metrics() []metric {
+	databaseStats := server.database.Stats()
+
+	commandSamples := lo.FlatMap(server.latencies.Get(nil), func(latency stats.CommandLatency, _ int) []metricSample {
+		command := strings.ToLower(latency.Name)
+		return []metricSample{
+			{labels: []lo.Entry[string, string]{{Key: "command", Value: command}, {Key: "result", Value: "ok"}}, value: float64(latency.Calls - latency.FailedCalls)},
+			{labels: []lo.Entry[string, string]{{Key: "command", Value: command}, {Key: "result", Value: "error"}}, value: float64(latency.FailedCalls)},
+		}
+	})
+
+	indexSamples := lo.Map(
+		[]lo.Entry[string, int]{
+			{Key: "value", Value: databaseStats.ValueIndexEntries},
+			{Key: "prefix", Value: databaseStats.PrefixIndexEntries},
+			{Key: "suffix", Value: databaseStats.SuffixIndexEntries},
+		},
+		func(index lo.Entry[string, int], _ int) metricSample {
+			return metricSample{labels: []lo.Entry[string, string]{{Key: "index", Value: index.Key}}, value: float64(index.Value)}
+		},
+	)
+
+	return []metric{
+		{name: "redigo_commands_total", help: "Commands processed by name and result.", kind: COUNTER_METRIC, samples: commandSamples},
+		newSingleMetric("redigo_expired_keys_total", "Keys removed by the expiration listener.", COUNTER_METRIC, float64(databaseStats.ExpiredKeys)),
+		newSingleMetric("redigo_evicted_keys_total", "Keys removed to stay under the memory limit.", COUNTER_METRIC, float64(databaseStats.EvictedKeys)),
+		newSingleMetric("redigo_aof_flush_errors_total", "Failed AOF buffer flushes.", COUNTER_METRIC, float64(databaseStats.AofFlushErrors)),
+		newSingleMetric("redigo_snapshot_failures_total", "Failed snapshots.", COUNTER_METRIC, float64(databaseStats.SnapshotFailures)),
+		{
+			name: "redigo_snapshot_duration_seconds",
+			help: "Duration of the snapshots.",
+			kind: SUMMARY_METRIC,
+			samples: []metricSample{
+				{suffix: "_sum", value: databaseStats.SnapshotDuration.Seconds()},
+				{suffix: "_count", value: float64(databaseStats.Snapshots)},
+			},
+		},
+		newSingleMetric("redigo_keys", "Keys in the store.", GAUGE_METRIC, float64(databaseStats.Keys)),
+		newSingleMetric("redigo_dataset_memory_bytes", "Approximate memory of the keys and values.", GAUGE_METRIC, float64(databaseStats.UsedMemory)),
+		newSingleMetric("redigo_connected_clients", "Connected clients.", GAUGE_METRIC, float64(len(server.Sessions()))),
+		newSingleMetric("redigo_aof_buffer_length", "Commands waiting to be flushed to the AOF.", GAUGE_METRIC, float64(databaseStats.AofBufferLength)),
+		{name: "redigo_index_entries", help: "Entries of each reverse index.", kind: GAUGE_METRIC, samples: indexSamples},
+	}
+}
+
+// ListenMetrics serves the metrics at /metrics for Prometheus to scrape.
+func (server *Server) ListenMetrics(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", METRICS_CONTENT_TYPE)
+		for _, metric := range server.metrics() {
+			metric.writeTo(writer)
+		}
+	})
+
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: HTTP_READ_HEADER_TIMEOUT}
+
+	server.listenersMutex.Lock()
+	server.httpServers = append(server.httpServers, httpServer)
+	server.listenersMutex.Unlock()
+
+	go httpServer.Serve(listener)
+	return nil
+}
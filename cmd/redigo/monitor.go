@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"redigo/internal/pubsub"
+	"redigo/internal/redigo"
+
+	"github.com/samber/lo"
+)
+
+const MONITOR_CHANNEL = "monitor"
+
+const REDACTED_ARGUMENT = "(redacted)"
+
+// redactArguments hides the passwords of AUTH, HELLO AUTH and ACL SETUSER rules.
+func redactArguments(arguments []string) []string {
+	redacted := append([]string{}, arguments...)
+
+	switch strings.ToUpper(arguments[0]) {
+	case AUTH_COMMAND:
+		for index := 1; index < len(redacted); index++ {
+			redacted[index] = REDACTED_ARGUMENT
+		}
+	case HELLO_COMMAND:
+		for index := 2; index < len(redacted); index++ {
+			if strings.EqualFold(arguments[index], "AUTH") {
+				for passwordIndex := index + 1; passwordIndex <= index+2 && passwordIndex < len(redacted); passwordIndex++ {
+					redacted[passwordIndex] = REDACTED_ARGUMENT
+				}
+			}
+		}
+	case ACL_COMMAND:
+		for index := 2; index < len(redacted); index++ {
+			if strings.IndexAny(arguments[index], "><#!") == 0 {
+				redacted[index] = REDACTED_ARGUMENT
+			}
+		}
+	}
+
+	return redacted
+}
+
+// feedMonitors sends the command about to run to the MONITOR connections,
+// formatted as "timestamp [db address] "argument" ...".
+func (server *Server) feedMonitors(arguments []string, session *Session) {
+	if server.monitors.NumSubscribers([]string{MONITOR_CHANNEL})[0] == 0 {
+		return
+	}
+
+	now := time.Now()
+	quoted := lo.Map(redactArguments(arguments), func(argument string, _ int) string {
+		return strconv.Quote(argument)
+	})
+
+	server.monitors.Publish(MONITOR_CHANNEL, fmt.Sprintf(
+		"%d.%06d [%d %s] %s",
+		now.Unix(),
+		now.Nanosecond()/int(time.Microsecond),
+		session.db,
+		session.Address(),
+		strings.Join(quoted, " "),
+	))
+}
+
+func newMonitorResponse(message pubsub.Message) ClientResponse {
+	return NewSuccessResponse(message.Payload)
+}
+
+func (session *Session) isMonitoring() bool {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	return session.monitorSubscriber != nil
+}
+
+// closeMonitor stops feeding a closed connection.
+func (session *Session) closeMonitor() {
+	session.informationMutex.Lock()
+	subscriber := session.monitorSubscriber
+	session.informationMutex.Unlock()
+
+	if subscriber != nil {
+		session.server.monitors.Remove(subscriber)
+	}
+}
+
+func handleMonitorCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if response := requirePersistentConnection(arguments, session); response != nil {
+		return *response
+	}
+
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	if session.monitorSubscriber == nil {
+		session.monitorSubscriber = session.server.monitors.NewSubscriber()
+		session.server.monitors.Subscribe(session.monitorSubscriber, []string{MONITOR_CHANNEL})
+		go pushMessages(session, session.monitorSubscriber, newMonitorResponse)
+	}
+
+	return NewSuccessResponse("OK")
+}
+
+func init() {
+	RegisterCommand(Command{
+		Name:    MONITOR_COMMAND,
+		Arity:   1,
+		Flags:   []CommandFlag{ADMIN_FLAG, NOMULTI_FLAG},
+		Usage:   "MONITOR",
+		Handler: handleMonitorCommand,
+	})
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"redigo/internal/pubsub"
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+const (
+	PUBSUB_CHANNELS_SUBCOMMAND = "CHANNELS" // List the channels with subscribers
+	PUBSUB_NUMSUB_SUBCOMMAND   = "NUMSUB"   // Count the subscribers of channels
+	PUBSUB_NUMPAT_SUBCOMMAND   = "NUMPAT"   // Count the subscribed patterns
+)
+
+// subscriber returns the pub/sub subscriber of the session, creating it and
+// starting to push its messages on the first subscription.
+func (session *Session) subscriber() *pubsub.Subscriber {
+	session.informationMutex.Lock()
+	defer session.informationMutex.Unlock()
+
+	if session.pubsubSubscriber == nil {
+		session.pubsubSubscriber = session.server.pubsub.NewSubscriber()
+		go pushMessages(session, session.pubsubSubscriber, newMessageResponse)
+	}
+	return session.pubsubSubscriber
+}
+
+// subscriptionCounts returns the number of channels and patterns the session is subscribed to.
+func (session *Session) subscriptionCounts() (int, int) {
+	session.informationMutex.Lock()
+	subscriber := session.pubsubSubscriber
+	session.informationMutex.Unlock()
+
+	if subscriber == nil {
+		return 0, 0
+	}
+	return session.server.pubsub.Count(subscriber)
+}
+
+func (session *Session) isSubscribed() bool {
+	channels, patterns := session.subscriptionCounts()
+	return channels+patterns > 0
+}
+
+// closeSubscriber drops the subscriptions of a closed connection.
+func (session *Session) closeSubscriber() {
+	session.informationMutex.Lock()
+	subscriber := session.pubsubSubscriber
+	session.informationMutex.Unlock()
+
+	if subscriber != nil {
+		session.server.pubsub.Remove(subscriber)
+	}
+}
+
+// pushMessages writes the messages of subscriber to the connection, formatted
+// by newResponse, until it is removed, and drops the connection when the
+// subscriber cannot keep up. The overflow is watched separately since writing
+// to a slow client blocks.
+func pushMessages(session *Session, subscriber *pubsub.Subscriber, newResponse func(message pubsub.Message) ClientResponse) {
+	go func() {
+		select {
+		case <-subscriber.Overflowed():
+			fmt.Printf("Client %d could not keep up with its messages, closing the connection\n", session.id)
+			session.connection.Close()
+		case <-subscriber.Removed():
+		}
+	}()
+
+	for {
+		select {
+		case message := <-subscriber.Messages():
+			flush := len(subscriber.Messages()) == 0
+
+			var err error
+			if session.canReceive(message) {
+				err = session.pushResponse(newResponse(message), flush)
+			} else if flush {
+				err = session.flush()
+			}
+
+			if err != nil {
+				session.connection.Close()
+				return
+			}
+		case <-subscriber.Removed():
+			return
+		}
+	}
+}
+
+// canReceive reports whether the session user may read message, keyspace
+// notifications name a key and only reach the users allowed to access it.
+func (session *Session) canReceive(message pubsub.Message) bool {
+	key, isNotification := pubsub.NotificationKey(message)
+	return !isNotification || len(session.server.acl.AccessibleKeys(session.Username(), []string{key})) == 1
+}
+
+// pushResponse writes a response the client did not ask for, flushing it
+// unless more are about to follow.
+func (session *Session) pushResponse(response ClientResponse, flush bool) error {
+	session.writerMutex.Lock()
+	defer session.writerMutex.Unlock()
+
+	var err error
+	if session.isInline {
+		err = session.writer.WriteInline(response.ToString())
+	} else {
+		err = session.writer.WriteValue(response.Reply)
+	}
+
+	if err != nil || !flush {
+		return err
+	}
+	return session.writer.Flush()
+}
+
+func newMessageResponse(message pubsub.Message) ClientResponse {
+	return NewSuccessResponse(lo.Ternary(
+		message.Pattern == "",
+		fmt.Sprintf("message %s %s", message.Channel, message.Payload),
+		fmt.Sprintf("pmessage %s %s %s", message.Pattern, message.Channel, message.Payload),
+	)).WithReply(newMessagePush(message))
+}
+
+func newMessagePush(message pubsub.Message) resp.Value {
+	if message.Pattern != "" {
+		return resp.NewPush(lo.Map(
+			[]string{"pmessage", message.Pattern, message.Channel, message.Payload},
+			func(value string, _ int) resp.Value { return resp.NewBulkString(value) },
+		))
+	}
+
+	return resp.NewPush(lo.Map(
+		[]string{"message", message.Channel, message.Payload},
+		func(value string, _ int) resp.Value { return resp.NewBulkString(value) },
+	))
+}
+
+// newSubscriptionResponse confirms each (un)subscribed channel with a push of
+// the kind, the channel and the number of subscriptions left.
+func newSubscriptionResponse(kind string, names []string, counts []int) ClientResponse {
+	if len(names) == 0 {
+		push := resp.NewPush([]resp.Value{resp.NewBulkString(kind), resp.NewNull(), resp.NewInteger(0)})
+		return NewPushResponse(kind+" (nil) 0", []resp.Value{push})
+	}
+
+	pushes := lo.Map(names, func(name string, index int) resp.Value {
+		return resp.NewPush([]resp.Value{
+			resp.NewBulkString(kind),
+			resp.NewBulkString(name),
+			resp.NewInteger(int64(counts[index])),
+		})
+	})
+
+	lines := lo.Map(names, func(name string, index int) string {
+		return fmt.Sprintf("%s %s %d", kind, name, counts[index])
+	})
+
+	return NewPushResponse(strings.Join(lines, "\n"), pushes)
+}
+
+// confirmSubscription subscribes and sends the confirmation while holding
+// writerMutex, so that no message of the new subscriptions can be pushed before it.
+func (session *Session) confirmSubscription(subscribe func() ClientResponse) ClientResponse {
+	session.writerMutex.Lock()
+	defer session.writerMutex.Unlock()
+
+	response := subscribe()
+	if err := session.unsafeWriteResponse(response); err != nil {
+		return response
+	}
+	if err := session.writer.Flush(); err != nil {
+		return response
+	}
+
+	response.written = true
+	return response
+}
+
+func handleSubscribeCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if response := requirePersistentConnection(arguments, session); response != nil {
+		return *response
+	}
+
+	channels := arguments[1:]
+	subscriber := session.subscriber()
+	return session.confirmSubscription(func() ClientResponse {
+		counts := session.server.pubsub.Subscribe(subscriber, channels)
+		return newSubscriptionResponse("subscribe", channels, counts)
+	})
+}
+
+func handlePsubscribeCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if response := requirePersistentConnection(arguments, session); response != nil {
+		return *response
+	}
+
+	patterns := arguments[1:]
+	subscriber := session.subscriber()
+	return session.confirmSubscription(func() ClientResponse {
+		counts := session.server.pubsub.PSubscribe(subscriber, patterns)
+		return newSubscriptionResponse("psubscribe", patterns, counts)
+	})
+}
+
+func handleUnsubscribeCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if response := requirePersistentConnection(arguments, session); response != nil {
+		return *response
+	}
+
+	channels, counts := session.server.pubsub.Unsubscribe(session.subscriber(), arguments[1:])
+	return newSubscriptionResponse("unsubscribe", channels, counts)
+}
+
+func handlePunsubscribeCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if response := requirePersistentConnection(arguments, session); response != nil {
+		return *response
+	}
+
+	patterns, counts := session.server.pubsub.PUnsubscribe(session.subscriber(), arguments[1:])
+	return newSubscriptionResponse("punsubscribe", patterns, counts)
+}
+
+func handlePublishCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	receivers := session.server.pubsub.Publish(arguments[1], arguments[2])
+	return NewIntegerResponse(int64(receivers))
+}
+
+func handlePubsubCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	broker := session.server.pubsub
+
+	switch strings.ToUpper(arguments[1]) {
+	case PUBSUB_CHANNELS_SUBCOMMAND:
+		if len(arguments) > 3 {
+			return NewUsageErrorResponse("Usage: PUBSUB CHANNELS [pattern]")
+		}
+		channels := broker.Channels(lo.NthOr(arguments, 2, ""))
+		return NewSuccessResponse(strings.Join(channels, "\n")).WithReply(resp.NewBulkStringArray(channels))
+	case PUBSUB_NUMSUB_SUBCOMMAND:
+		channels := arguments[2:]
+		counts := broker.NumSubscribers(channels)
+
+		pairs := lo.FlatMap(channels, func(channel string, index int) []resp.Value {
+			return []resp.Value{resp.NewBulkString(channel), resp.NewInteger(int64(counts[index]))}
+		})
+		lines := lo.Map(channels, func(channel string, index int) string {
+			return fmt.Sprintf("%s %d", channel, counts[index])
+		})
+		return NewSuccessResponse(strings.Join(lines, "\n")).WithReply(resp.NewArray(pairs))
+	case PUBSUB_NUMPAT_SUBCOMMAND:
+		return NewIntegerResponse(int64(broker.NumPatterns()))
+	default:
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown PUBSUB subcommand '%v'", arguments[1]))
+	}
+}
+
+func init() {
+	lo.ForEach(
+		[]Command{
+			{Name: SUBSCRIBE_COMMAND, Arity: -2, Flags: []CommandFlag{PUBSUB_FLAG, NOMULTI_FLAG}, Usage: "SUBSCRIBE {channel} [channel ...]", Handler: handleSubscribeCommand},
+			{Name: UNSUBSCRIBE_COMMAND, Arity: -1, Flags: []CommandFlag{PUBSUB_FLAG, NOMULTI_FLAG}, Usage: "UNSUBSCRIBE [channel ...]", Handler: handleUnsubscribeCommand},
+			{Name: PSUBSCRIBE_COMMAND, Arity: -2, Flags: []CommandFlag{PUBSUB_FLAG, NOMULTI_FLAG}, Usage: "PSUBSCRIBE {pattern} [pattern ...]", Handler: handlePsubscribeCommand},
+			{Name: PUNSUBSCRIBE_COMMAND, Arity: -1, Flags: []CommandFlag{PUBSUB_FLAG, NOMULTI_FLAG}, Usage: "PUNSUBSCRIBE [pattern ...]", Handler: handlePunsubscribeCommand},
+			{Name: PUBLISH_COMMAND, Arity: 3, Usage: "PUBLISH {channel} {message}", Handler: handlePublishCommand},
+			{Name: PUBSUB_COMMAND, Arity: -2, Usage: "PUBSUB CHANNELS [pattern]|NUMSUB [channel ...]|NUMPAT", Handler: handlePubsubCommand},
+		},
+		func(command Command, _ int) {
+			RegisterCommand(command)
+		},
+	)
+}
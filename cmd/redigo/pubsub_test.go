@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"redigo/envs"
+	"redigo/internal/pubsub"
+)
+
+func TestKeyspaceNotificationsFollowTheAcl(t *testing.T) {
+	server := newTestServer(t, envs.Envs{})
+	if err := server.acl.SetUser("tenant", []string{"on", ">secret", "~tenant:*", "+@all"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+
+	connection, err := net.Dial("tcp", server.lastListenerAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer connection.Close()
+	connection.SetDeadline(time.Now().Add(5 * time.Second))
+	reader := bufio.NewReader(connection)
+
+	for _, command := range []string{"AUTH tenant secret", "PSUBSCRIBE __key*"} {
+		connection.Write([]byte(command + "\r\n"))
+		if line, err := reader.ReadString('\n'); err != nil || strings.HasPrefix(line, "ERR") || strings.Contains(line, "NOPERM") {
+			t.Fatalf("%s replied %q, %v", command, line, err)
+		}
+	}
+
+	notify := server.pubsub.KeyspaceNotifier(pubsub.KEYSPACE_EVENTS | pubsub.KEYEVENT_EVENTS | pubsub.ALL_EVENTS)
+	notify(0, pubsub.STRING_EVENTS, "set", "secret:1")
+	notify(0, pubsub.STRING_EVENTS, "set", "tenant:1")
+
+	for _, expected := range []string{"pmessage __key* __keyspace@0__:tenant:1 set\n", "pmessage __key* __keyevent@0__:set tenant:1\n"} {
+		if line, err := reader.ReadString('\n'); err != nil || line != expected {
+			t.Errorf("received %q, %v, expected %q", line, err, expected)
+		}
+	}
+}
+
+func TestSubscribeIsConfirmedBeforeAnyMessage(t *testing.T) {
+	server := newTestServer(t, envs.Envs{})
+	connection, client := net.Pipe()
+	defer client.Close()
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	session := NewSession(1, connection, server)
+	session.isInline = true
+	defer session.closeSubscriber()
+
+	lines := make(chan string, 2)
+	go func() {
+		reader := bufio.NewReader(client)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- line
+		}
+	}()
+
+	response := handleSubscribeCommand([]string{"SUBSCRIBE", "news"}, session, nil)
+	server.pubsub.Publish("news", "hello")
+	first := <-lines
+
+	writeResponse(session, response)
+	session.flush()
+	second := <-lines
+
+	if first != "subscribe news 1\n" || second != "message news hello\n" {
+		t.Errorf("received %q then %q, expected the confirmation before the message", first, second)
+	}
+}
+
+func TestNotificationKey(t *testing.T) {
+	tests := []struct {
+		message        pubsub.Message
+		key            string
+		isNotification bool
+	}{
+		{pubsub.Message{Channel: "__keyspace@3__:user:__:1", Payload: "set"}, "user:__:1", true},
+		{pubsub.Message{Channel: "__keyevent@0__:expired", Payload: "user:1"}, "user:1", true},
+		{pubsub.Message{Channel: "news", Payload: "user:1"}, "", false},
+	}
+
+	for _, test := range tests {
+		if key, isNotification := pubsub.NotificationKey(test.message); key != test.key || isNotification != test.isNotification {
+			t.Errorf("NotificationKey(%+v) = %q, %v, expected %q, %v", test.message, key, isNotification, test.key, test.isNotification)
+		}
+	}
+}
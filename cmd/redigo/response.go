@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+type ClientResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Error   error        `json:"error,omitempty"`
+	Reply   resp.Value   `json:"-"` // Typed reply sent to RESP clients, Message is sent to inline clients
+	Pushes  []resp.Value `json:"-"` // Sent instead of Reply when not empty, one push per (un)subscribed channel
+
+	written bool // Already sent by the handler, skipped by writeResponse, see confirmSubscription
+}
+
+type jsonClientResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Result  any    `json:"result"`
+	Code    string `json:"code,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func NewSuccessResponse(message string) ClientResponse {
+	return ClientResponse{
+		Success: true,
+		Message: message,
+		Error:   nil,
+		Reply:   resp.NewSimpleString(message),
+	}
+}
+
+func NewIntegerResponse(value int64) ClientResponse {
+	return ClientResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d", value),
+		Error:   nil,
+		Reply:   resp.NewInteger(value),
+	}
+}
+
+func NewBulkResponse(value string) ClientResponse {
+	return ClientResponse{
+		Success: true,
+		Message: value,
+		Error:   nil,
+		Reply:   resp.NewBulkString(value),
+	}
+}
+
+// NewErrorResponse prefixes the message with the code of the sentinel error err
+// wraps, so clients can match on "NOTFOUND", "USAGE"... instead of the text.
+func NewErrorResponse(err error) ClientResponse {
+	message := fmt.Sprintf("%s %v", redigoErrors.CodeOf(err), err)
+
+	return ClientResponse{
+		Success: false,
+		Message: message,
+		Error:   err,
+		Reply:   resp.NewError(message),
+	}
+}
+
+// NewPushResponse answers with several pushes, the way SUBSCRIBE confirms
+// each channel. Reply holds them as an array for the HTTP gateway.
+func NewPushResponse(message string, pushes []resp.Value) ClientResponse {
+	return ClientResponse{
+		Success: true,
+		Message: message,
+		Error:   nil,
+		Reply:   resp.NewArray(pushes),
+		Pushes:  pushes,
+	}
+}
+
+func NewNilResponse(err error) ClientResponse {
+	return NewErrorResponse(err).WithReply(resp.NewNull())
+}
+
+func NewUsageErrorResponse(usage string) ClientResponse {
+	message := fmt.Sprintf("%s %s", redigoErrors.CODE_USAGE, usage)
+
+	return ClientResponse{
+		Success: false,
+		Message: message,
+		Error:   redigoErrors.Wrapf(redigoErrors.ErrorInvalidUsage, "%s", usage),
+		Reply:   resp.NewError(message),
+	}
+}
+
+func (response ClientResponse) WithReply(reply resp.Value) ClientResponse {
+	response.Reply = reply
+	return response
+}
+
+func (response ClientResponse) ToString() string {
+	return response.Message + "\n"
+}
+
+// MarshalJSON exposes the typed reply as "result" and the error as its message.
+func (response ClientResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonClientResponse{
+		Success: response.Success,
+		Message: response.Message,
+		Result:  lo.Ternary(response.Success, replyToJson(response.Reply), nil),
+		Code: lo.TernaryF(
+			response.Error != nil,
+			func() string { return string(redigoErrors.CodeOf(response.Error)) },
+			func() string { return "" },
+		),
+		Error: lo.TernaryF(
+			response.Error != nil,
+			func() string { return response.Error.Error() },
+			func() string { return "" },
+		),
+	})
+}
+
+func replyToJson(reply resp.Value) any {
+	switch reply.Type {
+	case resp.INTEGER_TYPE:
+		return reply.Int
+	case resp.BOOLEAN_TYPE:
+		return reply.Int != 0
+	case resp.NULL_TYPE:
+		return nil
+	case resp.ARRAY_TYPE, resp.PUSH_TYPE:
+		return lo.Map(reply.Array, func(element resp.Value, _ int) any {
+			return replyToJson(element)
+		})
+	case resp.MAP_TYPE:
+		return lo.FromEntries(
+			lo.Map(lo.Chunk(reply.Array, 2), func(pair []resp.Value, _ int) lo.Entry[string, any] {
+				return lo.Entry[string, any]{Key: pair[0].Str, Value: replyToJson(pair[1])}
+			}),
+		)
+	default:
+		return reply.Str
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"redigo/envs"
+	"redigo/internal/acl"
+	"redigo/internal/pubsub"
+	"redigo/internal/redigo"
+	"redigo/internal/stats"
+
+	"github.com/samber/lo"
+)
+
+type Server struct {
+	database         *redigo.RedigoDB
+	config           envs.Envs
+	acl              *acl.Manager       // Users and permissions checked before each command
+	pubsub           *pubsub.Broker     // Channels and patterns clients subscribed to
+	monitors         *pubsub.Broker     // Feeds the processed commands to MONITOR connections
+	listeners        []net.Listener     // Every socket the server accepts connections on
+	httpServers      []*http.Server     // HTTP gateways, shut down separately from the listeners
+	listenersMutex   sync.Mutex         // Protects listeners and httpServers
+	sessions         map[int64]*Session // Connections currently being served, by client id
+	sessionsMutex    sync.Mutex         // Protects sessions, lastSessionId and isShuttingDown
+	lastSessionId    int64              // Id given to the most recently accepted connection
+	handlers         sync.WaitGroup     // Tracks running HandleConnection goroutines
+	shutdownRequests chan bool          // Receives the save flag of SHUTDOWN commands
+	isShuttingDown   bool               // Set once the server stopped accepting connections
+	stopped          chan struct{}      // Closed on shutdown to stop the server listeners
+
+	slowLog   *stats.SlowLog          // Commands slower than the configured threshold
+	latencies *stats.CommandLatencies // Calls and latency histogram of each command
+
+	startedAt           time.Time    // Reported as the uptime by INFO
+	receivedConnections atomic.Int64 // Connections accepted since startup
+	processedCommands   atomic.Int64 // Commands run since startup, queued ones counted when EXEC runs them
+}
+
+func NewServer(database *redigo.RedigoDB, config envs.Envs, aclManager *acl.Manager, broker *pubsub.Broker) *Server {
+	return &Server{
+		database:         database,
+		config:           config,
+		acl:              aclManager,
+		pubsub:           broker,
+		monitors:         pubsub.NewBroker(config.PubsubBufferSize),
+		slowLog:          stats.NewSlowLog(config.SlowlogMaxLen, config.SlowlogLogSlowerThan),
+		latencies:        stats.NewCommandLatencies(),
+		startedAt:        time.Now(),
+		sessions:         make(map[int64]*Session),
+		shutdownRequests: make(chan bool, 1),
+		stopped:          make(chan struct{}),
+	}
+}
+
+// Sessions returns the connected clients ordered by id.
+func (server *Server) Sessions() []*Session {
+	server.sessionsMutex.Lock()
+	defer server.sessionsMutex.Unlock()
+
+	sessions := lo.Values(server.sessions)
+	slices.SortFunc(sessions, func(first, second *Session) int {
+		return cmp.Compare(first.id, second.id)
+	})
+
+	return sessions
+}
+
+func (server *Server) Listen(network, address string) error {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+
+	server.serve(listener)
+	return nil
+}
+
+func (server *Server) ListenTLS(address string, tlsConfig *tls.Config) error {
+	listener, err := tls.Listen("tcp", address, tlsConfig)
+	if err != nil {
+		return err
+	}
+
+	server.serve(listener)
+	return nil
+}
+
+// ListenUnix serves a Unix domain socket at path, replacing a stale socket file
+// left by a previous run, created with the given permissions.
+func (server *Server) ListenUnix(path string, permissions os.FileMode) error {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := listenUnixSocket(path, permissions)
+	if err != nil {
+		return err
+	}
+
+	server.serve(listener)
+	return nil
+}
+
+func (server *Server) serve(listener net.Listener) {
+	server.listenersMutex.Lock()
+	server.listeners = append(server.listeners, listener)
+	server.listenersMutex.Unlock()
+
+	go server.accept(listener)
+}
+
+func (server *Server) accept(listener net.Listener) {
+	for {
+		connection, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		} else if err != nil {
+			continue
+		}
+
+		server.sessionsMutex.Lock()
+		if server.isShuttingDown {
+			server.sessionsMutex.Unlock()
+			connection.Close()
+			continue
+		}
+
+		if server.config.MaxClients > 0 && len(server.sessions) >= server.config.MaxClients {
+			server.sessionsMutex.Unlock()
+			connection.Write([]byte("-ERR max number of clients reached\r\n"))
+			connection.Close()
+			continue
+		}
+
+		server.receivedConnections.Add(1)
+		server.lastSessionId++
+		session := NewSession(server.lastSessionId, connection, server)
+		server.sessions[session.id] = session
+		server.handlers.Add(1)
+		server.sessionsMutex.Unlock()
+
+		go func() {
+			defer server.handlers.Done()
+			defer server.removeSession(session)
+			HandleConnection(session)
+		}()
+	}
+}
+
+func (server *Server) nextSessionId() int64 {
+	server.sessionsMutex.Lock()
+	defer server.sessionsMutex.Unlock()
+
+	server.lastSessionId++
+	return server.lastSessionId
+}
+
+func (server *Server) removeSession(session *Session) {
+	server.sessionsMutex.Lock()
+	defer server.sessionsMutex.Unlock()
+
+	delete(server.sessions, session.id)
+}
+
+// RequestShutdown asks WaitForShutdown to return, it is used by the SHUTDOWN command.
+func (server *Server) RequestShutdown(save bool) {
+	select {
+	case server.shutdownRequests <- save:
+	default:
+	}
+}
+
+// WaitForShutdown blocks until SIGINT, SIGTERM or a SHUTDOWN command is received
+// and returns whether a final snapshot should be taken.
+func (server *Server) WaitForShutdown() bool {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case receivedSignal := <-signals:
+		fmt.Printf("Received %v, shutting down\n", receivedSignal)
+		return server.config.SaveOnShutdown
+	case save := <-server.shutdownRequests:
+		fmt.Println("SHUTDOWN requested, shutting down")
+		return save
+	}
+}
+
+// Shutdown stops accepting connections, lets every connection finish the commands
+// it already received, then flushes and closes the database.
+func (server *Server) Shutdown(save bool) error {
+	server.listenersMutex.Lock()
+	for _, listener := range server.listeners {
+		listener.Close()
+	}
+	server.listenersMutex.Unlock()
+
+	close(server.stopped)
+
+	server.sessionsMutex.Lock()
+	server.isShuttingDown = true
+	for _, session := range server.sessions {
+		session.connection.SetReadDeadline(time.Now())
+	}
+	server.sessionsMutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		server.handlers.Wait()
+		close(drained)
+	}()
+
+	httpContext, cancelHttp := context.WithTimeout(context.Background(), server.config.ShutdownTimeout)
+	defer cancelHttp()
+	server.shutdownHttpServers(httpContext)
+
+	select {
+	case <-drained:
+	case <-time.After(server.config.ShutdownTimeout):
+		fmt.Println("Shutdown timeout reached, closing remaining connections")
+		server.sessionsMutex.Lock()
+		for _, session := range server.sessions {
+			session.connection.Close()
+		}
+		server.sessionsMutex.Unlock()
+	}
+
+	return server.database.Shutdown(save)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"redigo/envs"
+	"redigo/internal/acl"
+	"redigo/internal/pubsub"
+	"redigo/internal/redigo"
+)
+
+// newTestServer starts an in-memory server without listeners, shut down at the end of the test.
+func newTestServer(t *testing.T, config envs.Envs) *Server {
+	t.Helper()
+
+	database, err := redigo.Open(redigo.WithInMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager, err := acl.NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.ShutdownTimeout = time.Second
+	server := NewServer(database, config, manager, pubsub.NewBroker(16))
+	t.Cleanup(func() { server.Shutdown(false) })
+	return server
+}
+
+// lastListenerAddress returns the address of the most recently started listener.
+func (server *Server) lastListenerAddress() string {
+	server.listenersMutex.Lock()
+	defer server.listenersMutex.Unlock()
+
+	return server.listeners[len(server.listeners)-1].Addr().String()
+}
+
+// sendInline writes an inline command on connection and returns the first line of the reply.
+func sendInline(connection net.Conn, command string) (string, error) {
+	connection.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := connection.Write([]byte(command + "\r\n")); err != nil {
+		return "", err
+	}
+
+	line, err := bufio.NewReader(connection).ReadString('\n')
+	return line, err
+}
+
+func TestListenServesCommands(t *testing.T) {
+	server := newTestServer(t, envs.Envs{})
+	if err := server.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+
+	connection, err := net.Dial("tcp", server.lastListenerAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer connection.Close()
+
+	if reply, err := sendInline(connection, "PING"); err != nil || reply != "PONG\n" {
+		t.Errorf("PING replied %q, %v", reply, err)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/internal/stats"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+const (
+	SLOWLOG_GET_SUBCOMMAND   = "GET"   // Get the most recent slow commands
+	SLOWLOG_LEN_SUBCOMMAND   = "LEN"   // Get the number of slow commands logged
+	SLOWLOG_RESET_SUBCOMMAND = "RESET" // Empty the slow log
+
+	LATENCY_HISTOGRAM_SUBCOMMAND = "HISTOGRAM" // Get the latency histograms of commands
+)
+
+const SLOWLOG_DEFAULT_GET_COUNT = 10
+
+// runCommand calls the handler of command, counts it and records its latency.
+func runCommand(command *Command, arguments []string, session *Session, store redigo.Store) ClientResponse {
+	start := time.Now()
+	response := command.Handler(arguments, session, store)
+	duration := time.Since(start)
+
+	session.server.processedCommands.Add(1)
+	session.server.latencies.Record(command.Name, duration, !response.Success)
+	session.server.slowLog.Record(duration, redactArguments(arguments), session.Address(), session.Name())
+
+	return response
+}
+
+func newSlowLogEntryReply(entry stats.SlowLogEntry) resp.Value {
+	return resp.NewArray([]resp.Value{
+		resp.NewInteger(entry.Id),
+		resp.NewInteger(entry.Timestamp.Unix()),
+		resp.NewInteger(entry.Duration.Microseconds()),
+		resp.NewBulkStringArray(entry.Arguments),
+		resp.NewBulkString(entry.Address),
+		resp.NewBulkString(entry.ClientName),
+	})
+}
+
+func handleSlowlogCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	slowLog := session.server.slowLog
+
+	switch strings.ToUpper(arguments[1]) {
+	case SLOWLOG_GET_SUBCOMMAND:
+		if len(arguments) > 3 {
+			return NewUsageErrorResponse("Usage: SLOWLOG GET [count]")
+		}
+
+		count := int64(SLOWLOG_DEFAULT_GET_COUNT)
+		if len(arguments) == 3 {
+			var err error
+			if count, err = strconv.ParseInt(arguments[2], 10, 64); err != nil || count < -1 {
+				return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidArgument, "invalid count '%v'", arguments[2]))
+			}
+		}
+
+		entries := slowLog.Get(int(count))
+		lines := lo.Map(entries, func(entry stats.SlowLogEntry, _ int) string {
+			return fmt.Sprintf(
+				"%d %d %dus %s %s: %s",
+				entry.Id,
+				entry.Timestamp.Unix(),
+				entry.Duration.Microseconds(),
+				entry.Address,
+				entry.ClientName,
+				strings.Join(entry.Arguments, " "),
+			)
+		})
+		return NewSuccessResponse(strings.Join(lines, "\n")).
+			WithReply(resp.NewArray(lo.Map(entries, func(entry stats.SlowLogEntry, _ int) resp.Value {
+				return newSlowLogEntryReply(entry)
+			})))
+	case SLOWLOG_LEN_SUBCOMMAND:
+		return NewIntegerResponse(int64(slowLog.Len()))
+	case SLOWLOG_RESET_SUBCOMMAND:
+		slowLog.Reset()
+		return NewSuccessResponse("OK")
+	default:
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown SLOWLOG subcommand '%v'", arguments[1]))
+	}
+}
+
+// newLatencyReply maps the number of calls and, for each bound in
+// microseconds, the number of calls that took at most that long.
+func newLatencyReply(latency stats.CommandLatency) resp.Value {
+	cumulativeBuckets := latency.CumulativeBuckets()
+
+	histogram := lo.FlatMap(stats.LATENCY_BUCKETS, func(bound time.Duration, index int) []resp.Value {
+		return []resp.Value{resp.NewInteger(bound.Microseconds()), resp.NewInteger(cumulativeBuckets[index])}
+	})
+
+	return resp.NewMap(
+		resp.NewBulkString("calls"), resp.NewInteger(latency.Calls),
+		resp.NewBulkString("histogram_usec"), resp.NewMap(histogram...),
+	)
+}
+
+func handleLatencyCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	switch strings.ToUpper(arguments[1]) {
+	case LATENCY_HISTOGRAM_SUBCOMMAND:
+		names := lo.Map(arguments[2:], func(name string, _ int) string { return strings.ToUpper(name) })
+		latencies := session.server.latencies.Get(names)
+
+		lines := lo.Map(latencies, func(latency stats.CommandLatency, _ int) string {
+			return fmt.Sprintf(
+				"%s calls=%d usec=%d usec_per_call=%.2f",
+				strings.ToLower(latency.Name),
+				latency.Calls,
+				latency.TotalDuration.Microseconds(),
+				float64(latency.TotalDuration.Microseconds())/float64(latency.Calls),
+			)
+		})
+		pairs := lo.FlatMap(latencies, func(latency stats.CommandLatency, _ int) []resp.Value {
+			return []resp.Value{resp.NewBulkString(strings.ToLower(latency.Name)), newLatencyReply(latency)}
+		})
+
+		return NewSuccessResponse(strings.Join(lines, "\n")).WithReply(resp.NewMap(pairs...))
+	default:
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorUnknownCommand, "unknown LATENCY subcommand '%v'", arguments[1]))
+	}
+}
+
+func init() {
+	lo.ForEach(
+		[]Command{
+			{Name: SLOWLOG_COMMAND, Arity: -2, Flags: []CommandFlag{ADMIN_FLAG}, Usage: "SLOWLOG GET [count]|LEN|RESET", Handler: handleSlowlogCommand},
+			{Name: LATENCY_COMMAND, Arity: -2, Flags: []CommandFlag{ADMIN_FLAG}, Usage: "LATENCY HISTOGRAM [command ...]", Handler: handleLatencyCommand},
+		},
+		func(command Command, _ int) {
+			RegisterCommand(command)
+		},
+	)
+}
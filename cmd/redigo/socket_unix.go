@@ -0,0 +1,18 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// listenUnixSocket creates the socket under a umask matching permissions, so
+// that it is never reachable with wider permissions before a chmod.
+func listenUnixSocket(path string, permissions os.FileMode) (net.Listener, error) {
+	previousUmask := syscall.Umask(int(^permissions & os.ModePerm))
+	defer syscall.Umask(previousUmask)
+
+	return net.Listen("unix", path)
+}
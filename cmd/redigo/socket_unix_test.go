@@ -0,0 +1,40 @@
+//go:build unix
+
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"redigo/envs"
+)
+
+func TestListenUnixPermissions(t *testing.T) {
+	for _, permissions := range []os.FileMode{0700, 0660} {
+		server := newTestServer(t, envs.Envs{})
+		path := filepath.Join(t.TempDir(), "redigo.sock")
+
+		if err := server.ListenUnix(path, permissions); err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != permissions {
+			t.Errorf("socket created with %v, expected %v", info.Mode().Perm(), permissions)
+		}
+
+		connection, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reply, err := sendInline(connection, "PING"); err != nil || reply != "PONG\n" {
+			t.Errorf("PING replied %q, %v", reply, err)
+		}
+		connection.Close()
+	}
+}
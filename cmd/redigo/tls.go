@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"redigo/envs"
+)
+
+// loadTlsConfig builds the TLS configuration of the TLS listener. When a CA
+// certificate is configured, clients presenting a certificate are verified
+// against it, and TLS_AUTH_CLIENTS makes that certificate mandatory.
+func loadTlsConfig(config envs.Envs) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(config.TlsCertFile, config.TlsKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if config.TlsCaCertFile == "" {
+		if config.TlsAuthClients {
+			return nil, fmt.Errorf("TLS_AUTH_CLIENTS requires TLS_CA_CERT_FILE")
+		}
+		return tlsConfig, nil
+	}
+
+	caCertificate, err := os.ReadFile(config.TlsCaCertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS CA certificate: %w", err)
+	}
+
+	clientCertificatePool := x509.NewCertPool()
+	if !clientCertificatePool.AppendCertsFromPEM(caCertificate) {
+		return nil, fmt.Errorf("no valid certificate found in %s", config.TlsCaCertFile)
+	}
+
+	tlsConfig.ClientCAs = clientCertificatePool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if config.TlsAuthClients {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"redigo/envs"
+)
+
+type testCertificate struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	tls         tls.Certificate
+}
+
+// newTestCertificate creates a certificate for 127.0.0.1 signed by parent, or self-signed CA when parent is nil.
+func newTestCertificate(t *testing.T, name string, parent *testCertificate) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+	} else {
+		signer, signerKey = parent.certificate, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCertificate{
+		certificate: certificate,
+		key:         key,
+		tls:         tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+	}
+}
+
+// writePem writes the certificate and its key in directory and returns their paths.
+func (certificate *testCertificate) writePem(t *testing.T, directory string) (string, string) {
+	t.Helper()
+
+	keyDer, err := x509.MarshalECPrivateKey(certificate.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := certificate.certificate.Subject.CommonName
+	certificatePath := filepath.Join(directory, name+".crt")
+	keyPath := filepath.Join(directory, name+".key")
+
+	certificatePem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.certificate.Raw})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := os.WriteFile(certificatePath, certificatePem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certificatePath, keyPath
+}
+
+// startTlsServer serves TLS with a server certificate signed by ca and returns the listener address.
+func startTlsServer(t *testing.T, ca *testCertificate, config envs.Envs) string {
+	t.Helper()
+
+	directory := t.TempDir()
+	config.TlsCertFile, config.TlsKeyFile = newTestCertificate(t, "server", ca).writePem(t, directory)
+	if config.TlsCaCertFile != "" {
+		config.TlsCaCertFile, _ = ca.writePem(t, directory)
+	}
+
+	tlsConfig, err := loadTlsConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestServer(t, config)
+	if err := server.ListenTLS("127.0.0.1:0", tlsConfig); err != nil {
+		t.Fatal(err)
+	}
+	return server.lastListenerAddress()
+}
+
+// pingOverTls sends PING presenting client, when not nil, and returns the reply.
+func pingOverTls(address string, ca *testCertificate, client *testCertificate) (string, error) {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.certificate)
+
+	tlsConfig := &tls.Config{RootCAs: roots}
+	if client != nil {
+		// Sent even when its issuer is not one of the CAs the server asks for.
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &client.tls, nil
+		}
+	}
+
+	connection, err := tls.Dial("tcp", address, tlsConfig)
+	if err != nil {
+		return "", err
+	}
+	defer connection.Close()
+
+	return sendInline(connection, "PING")
+}
+
+func TestTlsHandshake(t *testing.T) {
+	ca := newTestCertificate(t, "ca", nil)
+	address := startTlsServer(t, ca, envs.Envs{})
+
+	if reply, err := pingOverTls(address, ca, nil); err != nil || reply != "PONG\n" {
+		t.Errorf("PING over TLS replied %q, %v", reply, err)
+	}
+
+	if _, err := tls.Dial("tcp", address, &tls.Config{RootCAs: x509.NewCertPool()}); err == nil {
+		t.Error("handshake succeeded without trusting the server certificate")
+	}
+}
+
+func TestMutualTls(t *testing.T) {
+	ca := newTestCertificate(t, "ca", nil)
+	client := newTestCertificate(t, "client", ca)
+	untrusted := newTestCertificate(t, "untrusted", newTestCertificate(t, "other-ca", nil))
+
+	tests := []struct {
+		name        string
+		authClients bool
+		client      *testCertificate
+		accepted    bool
+	}{
+		{"optional without certificate", false, nil, true},
+		{"optional with certificate", false, client, true},
+		{"optional with untrusted certificate", false, untrusted, false},
+		{"required without certificate", true, nil, false},
+		{"required with certificate", true, client, true},
+		{"required with untrusted certificate", true, untrusted, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			address := startTlsServer(t, ca, envs.Envs{TlsCaCertFile: "ca.crt", TlsAuthClients: test.authClients})
+
+			// With TLS 1.3 a rejected client certificate only surfaces on the first read.
+			reply, err := pingOverTls(address, ca, test.client)
+			if accepted := err == nil && reply == "PONG\n"; accepted != test.accepted {
+				t.Errorf("PING replied %q, %v, expected accepted=%v", reply, err, test.accepted)
+			}
+		})
+	}
+}
+
+func TestTlsAuthClientsRequiresCa(t *testing.T) {
+	ca := newTestCertificate(t, "ca", nil)
+	certificatePath, keyPath := ca.writePem(t, t.TempDir())
+
+	config := envs.Envs{TlsCertFile: certificatePath, TlsKeyFile: keyPath, TlsAuthClients: true}
+	if _, err := loadTlsConfig(config); err == nil {
+		t.Error("TLS_AUTH_CLIENTS without TLS_CA_CERT_FILE was accepted")
+	}
+}
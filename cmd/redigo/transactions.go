@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+type queuedCommand struct {
+	command   *Command
+	arguments []string
+}
+
+type pendingTransaction struct {
+	commands []queuedCommand
+	hasError bool // Set when a command was rejected while queueing, EXEC then discards the transaction
+}
+
+// failTransaction marks the transaction being queued as failed when response
+// rejects a command, so that EXEC discards it instead of running half of it.
+func (session *Session) failTransaction(response ClientResponse) ClientResponse {
+	if session.transaction != nil {
+		session.transaction.hasError = true
+	}
+	return response
+}
+
+func (session *Session) unwatch() {
+	if session.watcher != nil {
+		session.server.database.Unwatch(session.watcher)
+	}
+}
+
+// queueCommand adds a command to the transaction. The commands not allowed
+// inside a transaction, such as a nested MULTI or WATCH, are refused without
+// discarding it, only unknown commands and wrong arities do.
+func queueCommand(command *Command, arguments []string, session *Session) ClientResponse {
+	if command.HasFlag(NOMULTI_FLAG) {
+		return NewErrorResponse(redigoErrors.Wrapf(
+			redigoErrors.ErrorInvalidUsage,
+			"'%s' is not allowed inside a transaction",
+			strings.ToLower(command.Name),
+		))
+	}
+
+	session.transaction.commands = append(session.transaction.commands, queuedCommand{command, arguments})
+	return NewSuccessResponse("QUEUED")
+}
+
+func handleMultiCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if response := requirePersistentConnection(arguments, session); response != nil {
+		return *response
+	}
+
+	session.transaction = &pendingTransaction{}
+	return NewSuccessResponse("OK")
+}
+
+func handleExecCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if session.transaction == nil {
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidUsage, "EXEC without MULTI"))
+	}
+
+	pending := session.transaction
+	session.transaction = nil
+
+	if pending.hasError {
+		session.unwatch()
+		return NewErrorResponse(redigoErrors.Wrapf(
+			redigoErrors.ErrorTransactionAborted,
+			"transaction discarded because of previous errors",
+		))
+	}
+
+	var responses []ClientResponse
+	err := session.server.database.RunTransaction(session.watcher, func(transaction *redigo.Transaction) {
+		responses = lo.Map(pending.commands, func(queued queuedCommand, _ int) ClientResponse {
+			return runCommand(queued.command, queued.arguments, session, transaction.Database(session.db))
+		})
+	})
+
+	if errors.Is(err, redigoErrors.ErrorTransactionAborted) {
+		return NewNilResponse(redigoErrors.Wrapf(err, "transaction aborted, a watched key was modified"))
+	}
+
+	lines := lo.Map(responses, func(response ClientResponse, _ int) string { return response.Message })
+	replies := lo.Map(responses, func(response ClientResponse, _ int) resp.Value { return response.Reply })
+
+	return NewSuccessResponse(strings.Join(lines, "\n")).WithReply(resp.NewArray(replies))
+}
+
+func handleDiscardCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if session.transaction == nil {
+		return NewErrorResponse(redigoErrors.Wrapf(redigoErrors.ErrorInvalidUsage, "DISCARD without MULTI"))
+	}
+
+	session.transaction = nil
+	session.unwatch()
+	return NewSuccessResponse("OK")
+}
+
+func handleWatchCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	if response := requirePersistentConnection(arguments, session); response != nil {
+		return *response
+	}
+
+	if session.watcher == nil {
+		session.watcher = redigo.NewWatcher()
+	}
+
+	session.database().Watch(session.watcher, arguments[1:])
+	return NewSuccessResponse("OK")
+}
+
+func handleUnwatchCommand(arguments []string, session *Session, store redigo.Store) ClientResponse {
+	session.unwatch()
+	return NewSuccessResponse("OK")
+}
+
+func init() {
+	lo.ForEach(
+		[]Command{
+			{Name: MULTI_COMMAND, Arity: 1, Flags: []CommandFlag{NOMULTI_FLAG}, Usage: "MULTI", Handler: handleMultiCommand},
+			{Name: EXEC_COMMAND, Arity: 1, Usage: "EXEC", Handler: handleExecCommand},
+			{Name: DISCARD_COMMAND, Arity: 1, Usage: "DISCARD", Handler: handleDiscardCommand},
+			{Name: WATCH_COMMAND, Arity: -2, Flags: []CommandFlag{NOMULTI_FLAG}, FirstKey: 1, LastKey: -1, KeyStep: 1, Usage: "WATCH {key} [key ...]", Handler: handleWatchCommand},
+			{Name: UNWATCH_COMMAND, Arity: 1, Flags: []CommandFlag{NOMULTI_FLAG}, Usage: "UNWATCH", Handler: handleUnwatchCommand},
+		},
+		func(command Command, _ int) {
+			RegisterCommand(command)
+		},
+	)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"redigo/envs"
+)
+
+func newTransactionSession(t *testing.T) *Session {
+	connection, client := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+
+	return NewSession(1, connection, newTestServer(t, envs.Envs{}))
+}
+
+func TestNoMultiCommandsKeepTheTransaction(t *testing.T) {
+	session := newTransactionSession(t)
+
+	steps := []struct {
+		command string
+		success bool
+	}{
+		{"MULTI", true},
+		{"SET a 1", true},
+		{"MULTI", false},
+		{"WATCH a", false},
+		{"UNWATCH", false},
+		{"SET b 2", true},
+		{"EXEC", true},
+		{"GET b", true},
+	}
+
+	for _, step := range steps {
+		if response := HandleCommand(strings.Fields(step.command), session); response.Success != step.success {
+			t.Fatalf("%s replied %q, expected success %v", step.command, response.Message, step.success)
+		}
+	}
+}
+
+func TestRejectedCommandsDiscardTheTransaction(t *testing.T) {
+	for _, rejected := range []string{"UNKNOWN a", "SET a"} {
+		session := newTransactionSession(t)
+
+		for _, command := range []string{"MULTI", "SET a 1", rejected} {
+			HandleCommand(strings.Fields(command), session)
+		}
+
+		if response := HandleCommand([]string{"EXEC"}, session); response.Success || !strings.HasPrefix(response.Message, "EXECABORT") {
+			t.Errorf("EXEC after %s replied %q, expected EXECABORT", rejected, response.Message)
+		}
+		if response := HandleCommand([]string{"GET", "a"}, session); response.Success {
+			t.Errorf("GET a replied %q after a discarded transaction", response.Message)
+		}
+	}
+}
@@ -16,6 +16,28 @@ type Envs struct {
 	DataExpirationInterval time.Duration `env:"DATA_EXPIRATION_INTERVAL" envDefault:"1m"`
 	DefaultTTL int64 `env:"DEFAULT_TTL" envDefault:"0"`
 	RedigoRootDirPath string `env:"REDIGO_ROOT_DIR_PATH" envDefault:""`
+	MaxValueSize int64 `env:"MAX_VALUE_SIZE" envDefault:"536870912"`
+	SaveOnShutdown bool `env:"SAVE_ON_SHUTDOWN" envDefault:"true"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"10s"`
+	ClientIdleTimeout time.Duration `env:"CLIENT_IDLE_TIMEOUT" envDefault:"0s"`
+	MaxClients int `env:"MAX_CLIENTS" envDefault:"10000"`
+	TlsPort string `env:"TLS_PORT" envDefault:""`
+	TlsCertFile string `env:"TLS_CERT_FILE" envDefault:""`
+	TlsKeyFile string `env:"TLS_KEY_FILE" envDefault:""`
+	TlsCaCertFile string `env:"TLS_CA_CERT_FILE" envDefault:""`
+	TlsAuthClients bool `env:"TLS_AUTH_CLIENTS" envDefault:"false"`
+	UnixSocketPath string `env:"UNIX_SOCKET_PATH" envDefault:""`
+	UnixSocketPermissions string `env:"UNIX_SOCKET_PERMISSIONS" envDefault:"0700"`
+	HttpPort string `env:"HTTP_PORT" envDefault:""`
+	PubsubBufferSize int `env:"PUBSUB_BUFFER_SIZE" envDefault:"1024"`
+	NotifyKeyspaceEvents string `env:"NOTIFY_KEYSPACE_EVENTS" envDefault:""`
+	SlowlogLogSlowerThan time.Duration `env:"SLOWLOG_LOG_SLOWER_THAN" envDefault:"10ms"`
+	SlowlogMaxLen int `env:"SLOWLOG_MAX_LEN" envDefault:"128"`
+	MetricsPort string `env:"METRICS_PORT" envDefault:""`
+	MaxMemory int64 `env:"MAX_MEMORY" envDefault:"0"`
+	MaxMemoryPolicy string `env:"MAX_MEMORY_POLICY" envDefault:"noeviction"`
+	Databases int `env:"DATABASES" envDefault:"16"`
+	AclUsers []string `env:"ACL_USERS" envSeparator:";"`
 }
 
 func LoadEnv() {
@@ -0,0 +1,45 @@
+package envs
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const ENV_FILENAME = ".env"
+
+// Rewrite sets variables in the .env file, keeping its comments and other
+// variables, and appends the variables it did not define yet.
+func Rewrite(variables map[string]string) error {
+	content, err := os.ReadFile(ENV_FILENAME)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", ENV_FILENAME, err)
+	}
+
+	lines := lo.Ternary(len(content) > 0, strings.Split(strings.TrimSuffix(string(content), "\n"), "\n"), []string{})
+	written := map[string]bool{}
+
+	for index, line := range lines {
+		name, _, isVariable := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+
+		if value, exists := variables[name]; isVariable && exists && !strings.HasPrefix(name, "#") {
+			lines[index] = name + "=" + value
+			written[name] = true
+		}
+	}
+
+	missing := lo.Filter(lo.Keys(variables), func(name string, _ int) bool { return !written[name] })
+	slices.Sort(missing)
+	for _, name := range missing {
+		lines = append(lines, name+"="+variables[name])
+	}
+
+	if err := os.WriteFile(ENV_FILENAME, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ENV_FILENAME, err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package acl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"redigo/internal/redigo/errors"
+	"redigo/pkg/utils"
+
+	"github.com/samber/lo"
+)
+
+type Manager struct {
+	users      map[string]*User // Users by name
+	usersMutex sync.RWMutex     // Protects users
+	filePath   string           // File the users are loaded from and saved to, empty to disable persistence
+}
+
+// NewManager loads the users from filePath. When the file does not exist the
+// only user is "default", without password and allowed to run everything, so
+// no authentication is required.
+func NewManager(filePath string) (*Manager, error) {
+	manager := &Manager{
+		users:    map[string]*User{},
+		filePath: filePath,
+	}
+
+	if filePath != "" && utils.FileExists(filePath) {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ACL file: %w", err)
+		}
+
+		users := []*User{}
+		if err := json.Unmarshal(data, &users); err != nil {
+			return nil, fmt.Errorf("failed to parse ACL file: %w", err)
+		}
+
+		lo.ForEach(users, func(user *User, _ int) {
+			user.Commands = lo.Ternary(user.Commands == nil, map[string]bool{}, user.Commands)
+			manager.users[user.Name] = user
+		})
+	}
+
+	if _, exists := manager.users[DEFAULT_USERNAME]; !exists {
+		defaultUser := NewUser(DEFAULT_USERNAME)
+		lo.ForEach(
+			[]string{"on", "nopass", "allkeys", "allcommands"},
+			func(rule string, _ int) {
+				defaultUser.ApplyRule(rule)
+			},
+		)
+		manager.users[DEFAULT_USERNAME] = defaultUser
+	}
+
+	return manager, nil
+}
+
+// DefaultUser returns the user new connections are authenticated as, or an
+// empty name when they have to run AUTH first.
+func (manager *Manager) DefaultUser() string {
+	manager.usersMutex.RLock()
+	defer manager.usersMutex.RUnlock()
+
+	defaultUser := manager.users[DEFAULT_USERNAME]
+	if defaultUser != nil && defaultUser.Enabled && defaultUser.NoPass {
+		return DEFAULT_USERNAME
+	}
+	return ""
+}
+
+func (manager *Manager) Authenticate(username, password string) error {
+	manager.usersMutex.RLock()
+	defer manager.usersMutex.RUnlock()
+
+	user, exists := manager.users[username]
+	if !exists || !user.CheckPassword(password) {
+		return errors.ErrorInvalidCredentials
+	}
+	return nil
+}
+
+// Check verifies that username is allowed to run command on every key of keys.
+// Commands acting on the whole keyspace require access to all keys.
+func (manager *Manager) Check(username, command string, keys []string, requiresAllKeys bool) error {
+	manager.usersMutex.RLock()
+	defer manager.usersMutex.RUnlock()
+
+	user, exists := manager.users[username]
+	if !exists || !user.Enabled {
+		return errors.ErrorAuthenticationRequired
+	}
+
+	if !user.CanRun(command) {
+		return errors.Wrapf(errors.ErrorNoPermission, "user '%s' has no permissions to run the '%s' command", username, command)
+	}
+
+	if requiresAllKeys && !user.CanAccessAllKeys() {
+		return errors.Wrapf(errors.ErrorNoPermission, "user '%s' has no permissions to run the '%s' command on every key", username, command)
+	}
+
+	if deniedKey, denied := lo.Find(keys, func(key string) bool { return !user.CanAccessKey(key) }); denied {
+		return errors.Wrapf(errors.ErrorNoPermission, "user '%s' has no permissions to access the '%s' key", username, deniedKey)
+	}
+
+	return nil
+}
+
+// AccessibleKeys returns the keys of keys that username is allowed to access.
+func (manager *Manager) AccessibleKeys(username string, keys []string) []string {
+	manager.usersMutex.RLock()
+	defer manager.usersMutex.RUnlock()
+
+	user, exists := manager.users[username]
+	if !exists {
+		return []string{}
+	}
+
+	return lo.Filter(keys, func(key string, _ int) bool {
+		return user.CanAccessKey(key)
+	})
+}
+
+// SetUser creates the user if needed and applies the rules in order. The user
+// is left untouched when one of the rules is invalid.
+func (manager *Manager) SetUser(username string, rules []string) error {
+	manager.usersMutex.Lock()
+	defer manager.usersMutex.Unlock()
+
+	user := lo.Ternary(
+		lo.HasKey(manager.users, username),
+		func() *User { return manager.users[username].clone() },
+		func() *User { return NewUser(username) },
+	)()
+
+	for _, rule := range rules {
+		if err := user.ApplyRule(rule); err != nil {
+			return err
+		}
+	}
+
+	manager.users[username] = user
+	return manager.unsafeSave()
+}
+
+// SetUserDefinitions applies definitions of the form "{username} [rule ...]",
+// such as the ones given in the configuration. Blank definitions are ignored.
+func (manager *Manager) SetUserDefinitions(definitions []string) error {
+	for _, definition := range definitions {
+		fields := strings.Fields(definition)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if err := manager.SetUser(fields[0], fields[1:]); err != nil {
+			return fmt.Errorf("invalid definition of user '%s': %w", fields[0], err)
+		}
+	}
+	return nil
+}
+
+// DeleteUsers removes the given users, except "default", and returns how many were deleted.
+func (manager *Manager) DeleteUsers(usernames []string) (int, error) {
+	manager.usersMutex.Lock()
+	defer manager.usersMutex.Unlock()
+
+	deletedUsernames := lo.Filter(
+		lo.Uniq(usernames),
+		func(username string, _ int) bool {
+			return username != DEFAULT_USERNAME && lo.HasKey(manager.users, username)
+		},
+	)
+
+	lo.ForEach(deletedUsernames, func(username string, _ int) {
+		delete(manager.users, username)
+	})
+
+	if len(deletedUsernames) == 0 {
+		return 0, nil
+	}
+	return len(deletedUsernames), manager.unsafeSave()
+}
+
+func (manager *Manager) Usernames() []string {
+	manager.usersMutex.RLock()
+	defer manager.usersMutex.RUnlock()
+
+	usernames := lo.Keys(manager.users)
+	slices.Sort(usernames)
+	return usernames
+}
+
+// Describe returns the rules of every user ordered by name.
+func (manager *Manager) Describe() []string {
+	manager.usersMutex.RLock()
+	defer manager.usersMutex.RUnlock()
+
+	usernames := lo.Keys(manager.users)
+	slices.Sort(usernames)
+
+	return lo.Map(usernames, func(username string, _ int) string {
+		return manager.users[username].Describe()
+	})
+}
+
+func (manager *Manager) unsafeSave() error {
+	if manager.filePath == "" {
+		return nil
+	}
+
+	usernames := lo.Keys(manager.users)
+	slices.Sort(usernames)
+	users := lo.Map(usernames, func(username string, _ int) *User {
+		return manager.users[username]
+	})
+
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal users: %w", err)
+	}
+
+	temporaryPath := manager.filePath + ".tmp"
+	if err := os.WriteFile(temporaryPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write ACL file: %w", err)
+	}
+
+	return os.Rename(temporaryPath, manager.filePath)
+}
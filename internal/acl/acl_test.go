@@ -0,0 +1,87 @@
+package acl
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	redigoErrors "redigo/internal/redigo/errors"
+)
+
+func newTestManager(t *testing.T, rules map[string][]string) *Manager {
+	t.Helper()
+
+	manager, err := NewManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for username, userRules := range rules {
+		if err := manager.SetUser(username, userRules); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return manager
+}
+
+func TestCheck(t *testing.T) {
+	manager := newTestManager(t, map[string][]string{
+		"reader": {"on", ">secret", "~session:*", "-@all", "+get", "+searchvalue"},
+	})
+
+	if err := manager.Check("reader", "GET", []string{"session:1"}, false); err != nil {
+		t.Errorf("GET session:1 denied: %v", err)
+	}
+	if err := manager.Check("reader", "GET", []string{"user:1"}, false); !errors.Is(err, redigoErrors.ErrorNoPermission) {
+		t.Errorf("GET user:1 returned %v, expected ErrorNoPermission", err)
+	}
+	if err := manager.Check("reader", "SET", []string{"session:1"}, false); !errors.Is(err, redigoErrors.ErrorNoPermission) {
+		t.Errorf("SET session:1 returned %v, expected ErrorNoPermission", err)
+	}
+	if err := manager.Check("reader", "GET", []string{}, true); !errors.Is(err, redigoErrors.ErrorNoPermission) {
+		t.Errorf("GET on every key returned %v, expected ErrorNoPermission", err)
+	}
+	if err := manager.Check(DEFAULT_USERNAME, "FLUSHALL", []string{}, true); err != nil {
+		t.Errorf("FLUSHALL denied to the default user: %v", err)
+	}
+	if err := manager.Check("unknown", "GET", []string{}, false); !errors.Is(err, redigoErrors.ErrorAuthenticationRequired) {
+		t.Errorf("unknown user returned %v, expected ErrorAuthenticationRequired", err)
+	}
+}
+
+func TestAccessibleKeys(t *testing.T) {
+	manager := newTestManager(t, map[string][]string{
+		"reader": {"on", "nopass", "~session:*", "+searchprefix"},
+	})
+	keys := []string{"session:1", "user:1", "session:2"}
+
+	if accessible := manager.AccessibleKeys("reader", keys); !reflect.DeepEqual(accessible, []string{"session:1", "session:2"}) {
+		t.Errorf("reader accesses %v", accessible)
+	}
+	if accessible := manager.AccessibleKeys(DEFAULT_USERNAME, keys); !reflect.DeepEqual(accessible, keys) {
+		t.Errorf("default user accesses %v", accessible)
+	}
+	if accessible := manager.AccessibleKeys("unknown", keys); len(accessible) != 0 {
+		t.Errorf("unknown user accesses %v", accessible)
+	}
+}
+
+func TestSetUserDefinitions(t *testing.T) {
+	manager := newTestManager(t, nil)
+
+	err := manager.SetUserDefinitions([]string{"reader on >secret ~session:* +get", " ", "default off"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.Authenticate("reader", "secret"); err != nil {
+		t.Errorf("reader cannot authenticate: %v", err)
+	}
+	if manager.DefaultUser() != "" {
+		t.Error("default user is still enabled")
+	}
+
+	if err := manager.SetUserDefinitions([]string{"broken on +@unknown"}); !errors.Is(err, redigoErrors.ErrorInvalidAclRule) {
+		t.Errorf("invalid rule returned %v, expected ErrorInvalidAclRule", err)
+	}
+}
@@ -0,0 +1,183 @@
+package acl
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"slices"
+	"strings"
+
+	"redigo/internal/redigo/errors"
+	"redigo/pkg/utils"
+
+	"github.com/samber/lo"
+)
+
+const DEFAULT_USERNAME = "default"
+
+type User struct {
+	Name           string          `json:"name"`
+	Enabled        bool            `json:"enabled"`
+	NoPass         bool            `json:"nopass"`      // Any password is accepted
+	PasswordHashes []string        `json:"passwords"`   // Hex encoded SHA-256 of the accepted passwords
+	AllCommands    bool            `json:"allCommands"` // Whether commands are allowed unless listed in Commands
+	Commands       map[string]bool `json:"commands"`    // Lowercase command names explicitly allowed or denied
+	KeyPatterns    []string        `json:"keys"`        // Glob patterns of the keys the user can access
+}
+
+func NewUser(name string) *User {
+	return &User{
+		Name:           name,
+		PasswordHashes: []string{},
+		Commands:       map[string]bool{},
+		KeyPatterns:    []string{},
+	}
+}
+
+func HashPassword(password string) string {
+	hash := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(hash[:])
+}
+
+func (user *User) CheckPassword(password string) bool {
+	if !user.Enabled {
+		return false
+	}
+
+	if user.NoPass {
+		return true
+	}
+
+	passwordHash := HashPassword(password)
+	return lo.ContainsBy(
+		user.PasswordHashes,
+		func(hash string) bool {
+			return subtle.ConstantTimeCompare([]byte(hash), []byte(passwordHash)) == 1
+		},
+	)
+}
+
+func (user *User) CanRun(command string) bool {
+	if allowed, exists := user.Commands[strings.ToLower(command)]; exists {
+		return allowed
+	}
+	return user.AllCommands
+}
+
+func (user *User) CanAccessKey(key string) bool {
+	return lo.ContainsBy(
+		user.KeyPatterns,
+		func(pattern string) bool {
+			return utils.MatchGlob(pattern, key)
+		},
+	)
+}
+
+func (user *User) CanAccessAllKeys() bool {
+	return slices.Contains(user.KeyPatterns, "*")
+}
+
+// ApplyRule applies one ACL SETUSER rule: on, off, >password, <password,
+// #hash, !hash, nopass, resetpass, +command, -command, +@all, -@all,
+// allcommands, nocommands, ~pattern, allkeys, resetkeys or reset.
+func (user *User) ApplyRule(rule string) error {
+	keywordRules := map[string]func(){
+		"on":          func() { user.Enabled = true },
+		"off":         func() { user.Enabled = false },
+		"nopass":      func() { user.NoPass, user.PasswordHashes = true, []string{} },
+		"resetpass":   func() { user.NoPass, user.PasswordHashes = false, []string{} },
+		"allcommands": func() { user.AllCommands, user.Commands = true, map[string]bool{} },
+		"+@all":       func() { user.AllCommands, user.Commands = true, map[string]bool{} },
+		"nocommands":  func() { user.AllCommands, user.Commands = false, map[string]bool{} },
+		"-@all":       func() { user.AllCommands, user.Commands = false, map[string]bool{} },
+		"allkeys":     func() { user.KeyPatterns = []string{"*"} },
+		"~*":          func() { user.KeyPatterns = []string{"*"} },
+		"resetkeys":   func() { user.KeyPatterns = []string{} },
+		"reset": func() {
+			*user = *NewUser(user.Name)
+		},
+	}
+
+	if apply, exists := keywordRules[strings.ToLower(rule)]; exists {
+		apply()
+		return nil
+	}
+
+	if len(rule) < 2 {
+		return errors.Wrapf(errors.ErrorInvalidAclRule, "invalid ACL rule '%s'", rule)
+	}
+
+	payload := rule[1:]
+
+	switch rule[0] {
+	case '>':
+		user.addPasswordHash(HashPassword(payload))
+	case '<':
+		user.removePasswordHash(HashPassword(payload))
+	case '#':
+		if _, err := hex.DecodeString(payload); err != nil || len(payload) != sha256.Size*2 {
+			return errors.Wrapf(errors.ErrorInvalidAclRule, "'%s' is not a SHA-256 hex digest", rule)
+		}
+		user.addPasswordHash(strings.ToLower(payload))
+	case '!':
+		user.removePasswordHash(strings.ToLower(payload))
+	case '+', '-':
+		if strings.HasPrefix(payload, "@") {
+			return errors.Wrapf(errors.ErrorInvalidAclRule, "unknown command category '%s'", payload)
+		}
+		user.Commands[strings.ToLower(payload)] = rule[0] == '+'
+	case '~':
+		if !slices.Contains(user.KeyPatterns, payload) {
+			user.KeyPatterns = append(user.KeyPatterns, payload)
+		}
+	default:
+		return errors.Wrapf(errors.ErrorInvalidAclRule, "invalid ACL rule '%s'", rule)
+	}
+
+	return nil
+}
+
+// Describe formats the user as a list of rules, the way ACL LIST reports it.
+func (user *User) Describe() string {
+	rules := []string{"user", user.Name, lo.Ternary(user.Enabled, "on", "off")}
+
+	if user.NoPass {
+		rules = append(rules, "nopass")
+	}
+	rules = append(rules, lo.Map(user.PasswordHashes, func(hash string, _ int) string { return "#" + hash })...)
+
+	if slices.Contains(user.KeyPatterns, "*") {
+		rules = append(rules, "~*")
+	} else {
+		rules = append(rules, lo.Map(user.KeyPatterns, func(pattern string, _ int) string { return "~" + pattern })...)
+	}
+
+	rules = append(rules, lo.Ternary(user.AllCommands, "+@all", "-@all"))
+
+	commands := lo.Keys(user.Commands)
+	slices.Sort(commands)
+	rules = append(rules, lo.Map(commands, func(command string, _ int) string {
+		return lo.Ternary(user.Commands[command], "+", "-") + command
+	})...)
+
+	return strings.Join(rules, " ")
+}
+
+func (user *User) addPasswordHash(hash string) {
+	user.NoPass = false
+	if !slices.Contains(user.PasswordHashes, hash) {
+		user.PasswordHashes = append(user.PasswordHashes, hash)
+	}
+}
+
+func (user *User) removePasswordHash(hash string) {
+	user.PasswordHashes = lo.Without(user.PasswordHashes, hash)
+}
+
+func (user *User) clone() *User {
+	cloned := *user
+	cloned.PasswordHashes = slices.Clone(user.PasswordHashes)
+	cloned.KeyPatterns = slices.Clone(user.KeyPatterns)
+	cloned.Commands = lo.Assign(user.Commands)
+	return &cloned
+}
@@ -0,0 +1,234 @@
+package pubsub
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"redigo/pkg/utils"
+
+	"github.com/samber/lo"
+)
+
+type Message struct {
+	Pattern string // Pattern the channel matched, empty for channel subscriptions
+	Channel string
+	Payload string
+}
+
+// Subscriber buffers the messages published to its channels and patterns until
+// its connection writes them. When the buffer is full the subscriber is
+// considered too slow: it stops receiving messages and Overflowed is closed so
+// that its connection gets dropped.
+type Subscriber struct {
+	messages     chan Message
+	overflowed   chan struct{}
+	overflowOnce sync.Once
+	isOverflowed atomic.Bool
+	removed      chan struct{}
+	removeOnce   sync.Once
+
+	channels map[string]bool // Protected by the broker mutex
+	patterns map[string]bool // Protected by the broker mutex
+}
+
+func (subscriber *Subscriber) Messages() <-chan Message {
+	return subscriber.messages
+}
+
+// Overflowed is closed when a message could not be buffered.
+func (subscriber *Subscriber) Overflowed() <-chan struct{} {
+	return subscriber.overflowed
+}
+
+// Removed is closed once the subscriber has been removed from the broker.
+func (subscriber *Subscriber) Removed() <-chan struct{} {
+	return subscriber.removed
+}
+
+func (subscriber *Subscriber) deliver(message Message) bool {
+	if subscriber.isOverflowed.Load() {
+		return false
+	}
+
+	select {
+	case subscriber.messages <- message:
+		return true
+	default:
+		subscriber.overflowOnce.Do(func() {
+			subscriber.isOverflowed.Store(true)
+			close(subscriber.overflowed)
+		})
+		return false
+	}
+}
+
+type Broker struct {
+	channels   map[string]map[*Subscriber]bool // Subscribers of each channel
+	patterns   map[string]map[*Subscriber]bool // Subscribers of each glob pattern
+	mutex      sync.RWMutex                    // Protects channels, patterns and the subscriptions of every subscriber
+	bufferSize int                             // Messages buffered per subscriber before it overflows
+}
+
+func NewBroker(bufferSize int) *Broker {
+	return &Broker{
+		channels:   map[string]map[*Subscriber]bool{},
+		patterns:   map[string]map[*Subscriber]bool{},
+		bufferSize: max(bufferSize, 1),
+	}
+}
+
+func (broker *Broker) NewSubscriber() *Subscriber {
+	return &Subscriber{
+		messages:   make(chan Message, broker.bufferSize),
+		overflowed: make(chan struct{}),
+		removed:    make(chan struct{}),
+		channels:   map[string]bool{},
+		patterns:   map[string]bool{},
+	}
+}
+
+// Subscribe adds channels to the subscriptions and returns the number of
+// subscriptions, channels and patterns, after each of them.
+func (broker *Broker) Subscribe(subscriber *Subscriber, channels []string) []int {
+	return broker.subscribe(subscriber, channels, broker.channels, subscriber.channels)
+}
+
+func (broker *Broker) PSubscribe(subscriber *Subscriber, patterns []string) []int {
+	return broker.subscribe(subscriber, patterns, broker.patterns, subscriber.patterns)
+}
+
+// Unsubscribe removes channels from the subscriptions, or every channel when
+// none is given. It returns the removed channels and the number of
+// subscriptions left after each of them.
+func (broker *Broker) Unsubscribe(subscriber *Subscriber, channels []string) ([]string, []int) {
+	return broker.unsubscribe(subscriber, channels, broker.channels, subscriber.channels)
+}
+
+func (broker *Broker) PUnsubscribe(subscriber *Subscriber, patterns []string) ([]string, []int) {
+	return broker.unsubscribe(subscriber, patterns, broker.patterns, subscriber.patterns)
+}
+
+func (broker *Broker) subscribe(
+	subscriber *Subscriber,
+	names []string,
+	subscriptions map[string]map[*Subscriber]bool,
+	subscriberNames map[string]bool,
+) []int {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	return lo.Map(names, func(name string, _ int) int {
+		if !lo.HasKey(subscriptions, name) {
+			subscriptions[name] = map[*Subscriber]bool{}
+		}
+		subscriptions[name][subscriber] = true
+		subscriberNames[name] = true
+
+		return len(subscriber.channels) + len(subscriber.patterns)
+	})
+}
+
+func (broker *Broker) unsubscribe(
+	subscriber *Subscriber,
+	names []string,
+	subscriptions map[string]map[*Subscriber]bool,
+	subscriberNames map[string]bool,
+) ([]string, []int) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	if len(names) == 0 {
+		names = lo.Keys(subscriberNames)
+		slices.Sort(names)
+	}
+
+	counts := lo.Map(names, func(name string, _ int) int {
+		delete(subscriberNames, name)
+		if subscribers, exists := subscriptions[name]; exists {
+			delete(subscribers, subscriber)
+			if len(subscribers) == 0 {
+				delete(subscriptions, name)
+			}
+		}
+
+		return len(subscriber.channels) + len(subscriber.patterns)
+	})
+
+	return names, counts
+}
+
+// Remove drops every subscription of subscriber, it is called when its connection closes.
+func (broker *Broker) Remove(subscriber *Subscriber) {
+	broker.Unsubscribe(subscriber, nil)
+	broker.PUnsubscribe(subscriber, nil)
+	subscriber.removeOnce.Do(func() { close(subscriber.removed) })
+}
+
+// Count returns the number of channels and patterns subscriber is subscribed to.
+func (broker *Broker) Count(subscriber *Subscriber) (channels int, patterns int) {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+
+	return len(subscriber.channels), len(subscriber.patterns)
+}
+
+// Publish delivers payload to the subscribers of channel and of the patterns
+// matching it, and returns how many messages were buffered.
+func (broker *Broker) Publish(channel, payload string) int {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+
+	receivers := 0
+
+	for subscriber := range broker.channels[channel] {
+		if subscriber.deliver(Message{Channel: channel, Payload: payload}) {
+			receivers++
+		}
+	}
+
+	for pattern, subscribers := range broker.patterns {
+		if !utils.MatchGlob(pattern, channel) {
+			continue
+		}
+
+		for subscriber := range subscribers {
+			if subscriber.deliver(Message{Pattern: pattern, Channel: channel, Payload: payload}) {
+				receivers++
+			}
+		}
+	}
+
+	return receivers
+}
+
+// Channels returns the channels with at least one subscriber matching pattern,
+// or every one of them when pattern is empty.
+func (broker *Broker) Channels(pattern string) []string {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+
+	channels := lo.Filter(lo.Keys(broker.channels), func(channel string, _ int) bool {
+		return pattern == "" || utils.MatchGlob(pattern, channel)
+	})
+	slices.Sort(channels)
+	return channels
+}
+
+// NumSubscribers returns the number of subscribers of each channel, pattern subscriptions excluded.
+func (broker *Broker) NumSubscribers(channels []string) []int {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+
+	return lo.Map(channels, func(channel string, _ int) int {
+		return len(broker.channels[channel])
+	})
+}
+
+// NumPatterns returns the number of patterns with at least one subscriber.
+func (broker *Broker) NumPatterns() int {
+	broker.mutex.RLock()
+	defer broker.mutex.RUnlock()
+
+	return len(broker.patterns)
+}
@@ -0,0 +1,105 @@
+package pubsub
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeyspaceEvents selects the keyspace notifications published, it is parsed
+// from the same flags as the notify-keyspace-events setting of Redis.
+type KeyspaceEvents uint8
+
+const (
+	KEYSPACE_EVENTS KeyspaceEvents = 1 << iota // K: published on __keyspace@<db>__:<key> with the event as message
+	KEYEVENT_EVENTS                            // E: published on __keyevent@<db>__:<event> with the key as message
+	GENERIC_EVENTS                             // g: del, expire and persist
+	STRING_EVENTS                              // $: set
+	EXPIRED_EVENTS                             // x: keys removed because their TTL elapsed
+	EVICTED_EVENTS                             // e: keys removed to stay under the memory limit
+
+	ALL_EVENTS = GENERIC_EVENTS | STRING_EVENTS | EXPIRED_EVENTS | EVICTED_EVENTS // A: every event class
+)
+
+const (
+	KEYSPACE_CHANNEL_FORMAT = "__keyspace@%d__:%s"
+	KEYEVENT_CHANNEL_FORMAT = "__keyevent@%d__:%s"
+	KEYSPACE_CHANNEL_PREFIX = "__keyspace@"
+	KEYEVENT_CHANNEL_PREFIX = "__keyevent@"
+)
+
+var keyspaceEventFlags = []struct {
+	flag   rune
+	events KeyspaceEvents
+}{
+	{'K', KEYSPACE_EVENTS},
+	{'E', KEYEVENT_EVENTS},
+	{'g', GENERIC_EVENTS},
+	{'$', STRING_EVENTS},
+	{'x', EXPIRED_EVENTS},
+	{'e', EVICTED_EVENTS},
+	{'A', ALL_EVENTS},
+}
+
+// ParseKeyspaceEvents parses flags such as "KEA" or "Ex", an empty string disables notifications.
+func ParseKeyspaceEvents(flags string) (KeyspaceEvents, error) {
+	var events KeyspaceEvents
+
+	for _, flag := range flags {
+		found := false
+		for _, keyspaceEventFlag := range keyspaceEventFlags {
+			if keyspaceEventFlag.flag == flag {
+				events |= keyspaceEventFlag.events
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return 0, fmt.Errorf("invalid keyspace events flag '%c'", flag)
+		}
+	}
+
+	return events, nil
+}
+
+func (events KeyspaceEvents) String() string {
+	var builder strings.Builder
+
+	for _, keyspaceEventFlag := range keyspaceEventFlags {
+		if keyspaceEventFlag.events != ALL_EVENTS && events&keyspaceEventFlag.events != 0 {
+			builder.WriteRune(keyspaceEventFlag.flag)
+		}
+	}
+	return builder.String()
+}
+
+// NotificationKey returns the key a message of a keyspace or keyevent channel
+// is about, false for the messages of other channels.
+func NotificationKey(message Message) (string, bool) {
+	if database, found := strings.CutPrefix(message.Channel, KEYSPACE_CHANNEL_PREFIX); found {
+		_, key, found := strings.Cut(database, "__:")
+		return key, found
+	}
+	if strings.HasPrefix(message.Channel, KEYEVENT_CHANNEL_PREFIX) {
+		return message.Payload, true
+	}
+	return "", false
+}
+
+// KeyspaceNotifier returns a function publishing the events of the classes
+// selected by events, on the keyspace channel, the keyevent channel or both,
+// both named after the database of the key.
+func (broker *Broker) KeyspaceNotifier(events KeyspaceEvents) func(db int, class KeyspaceEvents, event string, key string) {
+	return func(db int, class KeyspaceEvents, event string, key string) {
+		if events&class == 0 {
+			return
+		}
+
+		if events&KEYSPACE_EVENTS != 0 {
+			broker.Publish(fmt.Sprintf(KEYSPACE_CHANNEL_FORMAT, db, key), event)
+		}
+		if events&KEYEVENT_EVENTS != 0 {
+			broker.Publish(fmt.Sprintf(KEYEVENT_CHANNEL_FORMAT, db, event), key)
+		}
+	}
+}
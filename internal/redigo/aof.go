@@ -20,6 +20,10 @@ func (database *RedigoDB) CloseAof() error {
 }
 
 func (database *RedigoDB) AddCommandsToAofBuffer(command types.Command) []types.Command {
+	if !database.config.Persistence {
+		return nil
+	}
+
 	database.aofCommandsBufferMutex.Lock()
 	database.aofCommandsBuffer = append(database.aofCommandsBuffer, command)
 	database.aofCommandsBufferMutex.Unlock()
@@ -27,11 +31,16 @@ func (database *RedigoDB) AddCommandsToAofBuffer(command types.Command) []types.
 	return database.aofCommandsBuffer
 }
 
+// commandRecorder receives the commands of a write, to append them to the AOF
+// buffer right away or once a transaction completes.
+type commandRecorder func(command types.Command)
+
+func (database *RedigoDB) recordCommand(command types.Command) {
+	database.AddCommandsToAofBuffer(command)
+}
+
 func (database *RedigoDB) LoadFromAof() error {
-	aofPath, err := utils.GetAOFPath()
-	if err != nil {
-		return fmt.Errorf("failed to get AOF path: %w", err)
-	}
+	aofPath := database.dataFilePath(utils.AOF_FILENAME)
 
 	if !utils.FileExists(aofPath) {
 		return nil
@@ -84,17 +93,26 @@ func (database *RedigoDB) handleCommand(command types.Command) error {
 		return fmt.Errorf("unknown command type: %s", command.Name)
 	}
 
+	if err := database.checkDatabaseIndex(command.Db); err != nil {
+		return err
+	}
+	target := database.Database(command.Db)
+
 	handlers := map[types.CommandName]func(types.Command) error{
-		types.SET:    database.handleSetCommand,
-		types.DELETE: database.handleDeleteCommand,
-		types.EXPIRE: database.handleExpireCommand,
+		types.SET:      target.handleSetCommand,
+		types.DELETE:   target.handleDeleteCommand,
+		types.EXPIRE:   target.handleExpireCommand,
+		types.FLUSHDB:  target.handleFlushDbCommand,
+		types.MULTI:    database.handleMultiCommand,
+		types.FLUSHALL: database.handleFlushAllCommand,
+		types.SWAPDB:   database.handleSwapDbCommand,
 	}
 
 	handler := handlers[command.Name]
 	return handler(command)
 }
 
-func (database *RedigoDB) handleSetCommand(command types.Command) error {
+func (database *Database) handleSetCommand(command types.Command) error {
 	value, err := DeserializeCommandValue(command.Value)
 	if err != nil {
 		return fmt.Errorf("failed to deserialize value: %w", err)
@@ -107,18 +125,58 @@ func (database *RedigoDB) handleSetCommand(command types.Command) error {
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
-	database.store[command.Key] = value
+	database.unsafeStoreValue(command.Key, value)
 	database.handleTtlRestoration(command)
 
 	return nil
 }
 
-func (database *RedigoDB) handleDeleteCommand(command types.Command) error {
+func (database *Database) handleDeleteCommand(command types.Command) error {
 	database.SafeRemoveKey(command.Key)
 	return nil
 }
 
-func (database *RedigoDB) handleExpireCommand(command types.Command) error {
+// handleMultiCommand replays the commands of a transaction, written as a
+// single AOF line so that a truncated file never holds half of it.
+func (database *RedigoDB) handleMultiCommand(command types.Command) error {
+	for _, transactionCommand := range command.Commands {
+		if err := database.handleCommand(transactionCommand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (database *Database) handleFlushDbCommand(command types.Command) error {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	database.unsafeFlush(func(types.Command) {})
+	return nil
+}
+
+func (database *RedigoDB) handleFlushAllCommand(command types.Command) error {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	database.unsafeFlushAll()
+	return nil
+}
+
+func (database *RedigoDB) handleSwapDbCommand(command types.Command) error {
+	second, err := database.parseTargetDatabase(command)
+	if err != nil {
+		return fmt.Errorf("failed to parse swapped database: %w", err)
+	}
+
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	database.unsafeSwapDatabases(command.Db, second)
+	return nil
+}
+
+func (database *Database) handleExpireCommand(command types.Command) error {
 	seconds, err := database.parseExpirationSeconds(command.Value)
 	if err != nil {
 		return fmt.Errorf("failed to parse expiration seconds: %w", err)
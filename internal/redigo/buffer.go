@@ -9,7 +9,12 @@ import (
 	"github.com/samber/lo"
 )
 
+// FlushBuffer writes the buffered commands to the AOF. The buffer is drained
+// under aofMutex so that a snapshot cannot truncate the AOF before they are written.
 func (database *RedigoDB) FlushBuffer() error {
+	database.aofMutex.Lock()
+	defer database.aofMutex.Unlock()
+
 	database.aofCommandsBufferMutex.Lock()
 
 	if len(database.aofCommandsBuffer) == 0 {
@@ -26,9 +31,6 @@ func (database *RedigoDB) FlushBuffer() error {
 	database.aofCommandsBuffer = database.aofCommandsBuffer[:0]
 	database.aofCommandsBufferMutex.Unlock()
 
-	database.aofMutex.Lock()
-	defer database.aofMutex.Unlock()
-
 	var accumulatedError error
 
 	lo.ForEach(
@@ -44,6 +46,7 @@ func (database *RedigoDB) FlushBuffer() error {
 		},
 	)
 
+	database.recordAofFlush(accumulatedError)
 	return accumulatedError
 }
 
@@ -61,8 +64,6 @@ func (database *RedigoDB) writeCommandToAof(command types.Command) error {
 }
 
 func (database *RedigoDB) StartBufferListener() {
-	ticker := time.NewTicker(database.envs.FlushBufferInterval)
-
 	flushHandler := func() {
 		err := database.FlushBuffer()
 		message := lo.Ternary(
@@ -73,7 +74,5 @@ func (database *RedigoDB) StartBufferListener() {
 		fmt.Println(message)
 	}
 
-	for range ticker.C {
-		flushHandler()
-	}
+	database.runTicker(func(config Config) time.Duration { return config.FlushBufferInterval }, flushHandler)
 }
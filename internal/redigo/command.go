@@ -2,6 +2,7 @@ package redigo
 
 import (
 	"fmt"
+	"redigo/internal/pubsub"
 	"redigo/internal/redigo/errors"
 	"redigo/internal/redigo/types"
 	"strconv"
@@ -17,18 +18,27 @@ type SetTypeResolution struct {
 	found     bool
 }
 
-func (database *RedigoDB) Set(key string, value any, ttl int64) error {
-	now := time.Now().Unix()
-
+func (database *Database) Set(key string, value any, ttl int64) error {
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
+	return database.unsafeSet(key, value, ttl, database.recordCommand)
+}
+
+func (database *Database) unsafeSet(key string, value any, ttl int64, record commandRecorder) error {
+	now := time.Now().Unix()
+
 	if _, exists := database.store[key]; exists {
 		return errors.ErrorKeyAlreadyExists
 	}
 
-	database.store[key] = value
+	if err := database.unsafeReserveMemory(database.unsafeEstimateWriteSize(key, value, ttl), record); err != nil {
+		return err
+	}
+
+	database.unsafeStoreValue(key, value)
 	database.addToIndex(key, value)
+	database.touchKey(key)
 
 	lo.Ternary(
 		ttl > 0,
@@ -36,75 +46,38 @@ func (database *RedigoDB) Set(key string, value any, ttl int64) error {
 		func() { delete(database.expirationKeys, key) },
 	)()
 
-	valueTypeMapping := map[string]func(any) (string, any, bool){
-		"string": func(value any) (string, any, bool) {
-			if val, ok := value.(string); ok {
-				return "string", val, true
-			}
-			return "", nil, false
-		},
-		"int": func(value any) (string, any, bool) {
-			if val, ok := value.(int); ok {
-				return "int", val, true
-			}
-			return "", nil, false
-		},
-		"bool": func(value any) (string, any, bool) {
-			if val, ok := value.(bool); ok {
-				return "bool", val, true
-			}
-			return "", nil, false
-		},
-		"float64": func(value any) (string, any, bool) {
-			if val, ok := value.(float64); ok {
-				return "float64", val, true
-			}
-			return "", nil, false
-		},
-	}
-
-	typeResult := lo.Reduce(
-		lo.Keys(valueTypeMapping),
-		func(
-			acc SetTypeResolution,
-			typeKey string,
-			_ int,
-		) SetTypeResolution {
-			if acc.found {
-				return acc
-			}
-			if valueType, rawValue, ok := valueTypeMapping[typeKey](value); ok {
-				return SetTypeResolution{valueType, rawValue, true}
-			}
-			return acc
-		},
-		SetTypeResolution{"", nil, false},
-	)
-
-	if !typeResult.found {
-		return errors.ErrorUnsupportedValueType
+	commandValue, err := SerializeCommandValue(value)
+	if err != nil {
+		return err
 	}
 
 	command := types.Command{
-		Name: "SET",
-		Key:  key,
-		Value: types.CommandValue{
-			Type:  typeResult.valueType,
-			Value: typeResult.rawValue,
-		},
+		Name:      "SET",
+		Db:        database.index,
+		Key:       key,
+		Value:     commandValue,
 		Ttl:       &ttl,
 		Timestamp: now,
 	}
 
-	database.AddCommandsToAofBuffer(command)
+	record(command)
+
+	database.notifyKeyspaceEvent(pubsub.STRING_EVENTS, SET_EVENT, key)
+	if ttl > 0 {
+		database.notifyKeyspaceEvent(pubsub.GENERIC_EVENTS, EXPIRE_EVENT, key)
+	}
 
 	return nil
 }
 
-func (database *RedigoDB) Get(key string) (any, error) {
+func (database *Database) Get(key string) (any, error) {
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
+	return database.unsafeGet(key, database.recordCommand)
+}
+
+func (database *Database) unsafeGet(key string, record commandRecorder) (any, error) {
 	if expireTime, exists := database.expirationKeys[key]; exists {
 		isExpired := time.Now().Unix() > expireTime
 
@@ -115,16 +88,19 @@ func (database *RedigoDB) Get(key string) (any, error) {
 
 				command := types.Command{
 					Name:      "DELETE",
+					Db:        database.index,
 					Key:       key,
 					Value:     types.CommandValue{},
 					Timestamp: time.Now().Unix(),
 				}
-				database.AddCommandsToAofBuffer(command)
+				record(command)
+				database.notifyKeyspaceEvent(pubsub.EXPIRED_EVENTS, EXPIRED_EVENT, key)
 
 				return nil, errors.ErrorKeyExpired
 			},
 			func() (any, error) {
 				if val, ok := database.store[key]; ok {
+					database.unsafeAccessKey(key)
 					return val, nil
 				}
 				return nil, errors.ErrorKeyNotFound
@@ -136,6 +112,7 @@ func (database *RedigoDB) Get(key string) (any, error) {
 	return lo.Ternary(
 		ok,
 		func() (any, error) {
+			database.unsafeAccessKey(key)
 			return value, nil
 		},
 		func() (any, error) {
@@ -144,33 +121,41 @@ func (database *RedigoDB) Get(key string) (any, error) {
 	)()
 }
 
-func (database *RedigoDB) Delete(key string) bool {
+func (database *Database) Delete(key string) bool {
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
-	if value, exists := database.store[key]; exists {
-		database.removeFromIndex(key, value)
+	return database.unsafeDelete(key, database.recordCommand)
+}
 
+func (database *Database) unsafeDelete(key string, record commandRecorder) bool {
+	if _, exists := database.store[key]; exists {
 		database.UnsafeRemoveKey(key)
 
 		command := types.Command{
 			Name:      "DELETE",
+			Db:        database.index,
 			Key:       key,
 			Value:     types.CommandValue{},
 			Timestamp: time.Now().Unix(),
 		}
 
-		database.AddCommandsToAofBuffer(command)
+		record(command)
+		database.notifyKeyspaceEvent(pubsub.GENERIC_EVENTS, DEL_EVENT, key)
 		return true
 	}
 
 	return false
 }
 
-func (database *RedigoDB) SetExpiry(key string, seconds int64) bool {
+func (database *Database) SetExpiry(key string, seconds int64) bool {
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
+	return database.unsafeSetExpiry(key, seconds, database.recordCommand)
+}
+
+func (database *Database) unsafeSetExpiry(key string, seconds int64, record commandRecorder) bool {
 	_, exists := database.store[key]
 	if !exists {
 		return false
@@ -217,6 +202,7 @@ func (database *RedigoDB) SetExpiry(key string, seconds int64) bool {
 
 	command := types.Command{
 		Name: "EXPIRE",
+		Db:   database.index,
 		Key:  key,
 		Value: types.CommandValue{
 			Type:  "float64",
@@ -225,11 +211,13 @@ func (database *RedigoDB) SetExpiry(key string, seconds int64) bool {
 		Timestamp: time.Now().Unix(),
 	}
 
-	database.AddCommandsToAofBuffer(command)
+	database.touchKey(key)
+	record(command)
+	database.notifyKeyspaceEvent(pubsub.GENERIC_EVENTS, lo.Ternary(seconds > 0, EXPIRE_EVENT, PERSIST_EVENT), key)
 	return true
 }
 
-func (database *RedigoDB) SearchByValue(value string) []string {
+func (database *Database) SearchByValue(value string) []string {
 	database.indexMutex.RLock()
 	defer database.indexMutex.RUnlock()
 
@@ -244,7 +232,7 @@ func (database *RedigoDB) SearchByValue(value string) []string {
 	)()
 }
 
-func (database *RedigoDB) SearchByKeyPrefix(prefix string) []string {
+func (database *Database) SearchByKeyPrefix(prefix string) []string {
 	database.indexMutex.RLock()
 	defer database.indexMutex.RUnlock()
 
@@ -259,7 +247,7 @@ func (database *RedigoDB) SearchByKeyPrefix(prefix string) []string {
 	)()
 }
 
-func (database *RedigoDB) SearchByKeySuffix(suffix string) []string {
+func (database *Database) SearchByKeySuffix(suffix string) []string {
 	database.indexMutex.RLock()
 	defer database.indexMutex.RUnlock()
 
@@ -274,13 +262,17 @@ func (database *RedigoDB) SearchByKeySuffix(suffix string) []string {
 	)()
 }
 
-func (database *RedigoDB) SearchByKeyContains(substring string) []string {
+func (database *Database) SearchByKeyContains(substring string) []string {
 	database.indexMutex.RLock()
 	defer database.indexMutex.RUnlock()
 
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
+	return database.unsafeSearchByKeyContains(substring)
+}
+
+func (database *Database) unsafeSearchByKeyContains(substring string) []string {
 	return lo.Filter(
 		lo.Keys(database.store),
 		func(key string, _ int) bool {
@@ -289,6 +281,64 @@ func (database *RedigoDB) SearchByKeyContains(substring string) []string {
 	)
 }
 
+// SerializeCommandValue tags value with its type for the AOF, the reverse of
+// DeserializeCommandValue.
+func SerializeCommandValue(value any) (types.CommandValue, error) {
+	valueTypeMapping := map[string]func(any) (string, any, bool){
+		"string": func(value any) (string, any, bool) {
+			if val, ok := value.(string); ok {
+				return "string", val, true
+			}
+			return "", nil, false
+		},
+		"int": func(value any) (string, any, bool) {
+			if val, ok := value.(int); ok {
+				return "int", val, true
+			}
+			return "", nil, false
+		},
+		"bool": func(value any) (string, any, bool) {
+			if val, ok := value.(bool); ok {
+				return "bool", val, true
+			}
+			return "", nil, false
+		},
+		"float64": func(value any) (string, any, bool) {
+			if val, ok := value.(float64); ok {
+				return "float64", val, true
+			}
+			return "", nil, false
+		},
+	}
+
+	typeResult := lo.Reduce(
+		lo.Keys(valueTypeMapping),
+		func(
+			acc SetTypeResolution,
+			typeKey string,
+			_ int,
+		) SetTypeResolution {
+			if acc.found {
+				return acc
+			}
+			if valueType, rawValue, ok := valueTypeMapping[typeKey](value); ok {
+				return SetTypeResolution{valueType, rawValue, true}
+			}
+			return acc
+		},
+		SetTypeResolution{"", nil, false},
+	)
+
+	if !typeResult.found {
+		return types.CommandValue{}, errors.ErrorUnsupportedValueType
+	}
+
+	return types.CommandValue{
+		Type:  typeResult.valueType,
+		Value: typeResult.rawValue,
+	}, nil
+}
+
 func DeserializeCommandValue(commandValue types.CommandValue) (any, error) {
 	deserializers := map[string]func(any) (any, error){
 		"string": func(value any) (any, error) {
@@ -334,6 +384,6 @@ func DeserializeCommandValue(commandValue types.CommandValue) (any, error) {
 }
 
 func IsValidCommandType(commandName types.CommandName) bool {
-	validCommands := []types.CommandName{types.SET, types.DELETE, types.EXPIRE}
+	validCommands := []types.CommandName{types.SET, types.DELETE, types.EXPIRE, types.MULTI, types.FLUSHDB, types.FLUSHALL, types.SWAPDB}
 	return lo.Contains(validCommands, commandName)
 }
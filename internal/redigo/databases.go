@@ -0,0 +1,263 @@
+package redigo
+
+import (
+	"fmt"
+	"redigo/internal/pubsub"
+	"redigo/internal/redigo/errors"
+	"redigo/internal/redigo/types"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// keyspace holds the keys of a logical database. It is protected by the store
+// mutex, except for the indexes which are protected by the index mutex.
+type keyspace struct {
+	store          map[string]any          // Main key-value store
+	expirationKeys map[string]int64        // Maps keys to their expiration timestamps
+	keyMetadata    map[string]*keyMetadata // Size and accesses of each key used by eviction
+
+	valueIndex  *types.ReverseIndex // Index for searching by exact value
+	prefixIndex *types.ReverseIndex // Index for searching by key prefix
+	suffixIndex *types.ReverseIndex // Index for searching by key suffix
+
+	indexesMemory int64 // Approximate memory of the three indexes, changed under both mutexes
+}
+
+func newKeyspace() *keyspace {
+	indexTypes := []types.IndexType{
+		types.VALUE_INDEX,
+		types.PREFIX_INDEX,
+		types.SUFFIX_INDEX,
+	}
+	indexes := lo.Map(
+		indexTypes,
+		func(indexType types.IndexType, _ int) *types.ReverseIndex {
+			return &types.ReverseIndex{
+				Type:    indexType,
+				Entries: make(map[string]*types.IndexEntry),
+			}
+		})
+
+	return &keyspace{
+		store:          make(map[string]any),
+		expirationKeys: make(map[string]int64),
+		keyMetadata:    make(map[string]*keyMetadata),
+		valueIndex:     indexes[0],
+		prefixIndex:    indexes[1],
+		suffixIndex:    indexes[2],
+	}
+}
+
+// Database is a logical database of a RedigoDB, selected by its number. It
+// shares the locks, the AOF and the memory limit of the other databases.
+type Database struct {
+	*RedigoDB
+	*keyspace
+	index int
+}
+
+// Database returns the logical database numbered index, from 0 to Databases() - 1.
+func (database *RedigoDB) Database(index int) *Database {
+	return &Database{RedigoDB: database, keyspace: database.keyspaces[index], index: index}
+}
+
+// Databases returns the number of logical databases.
+func (database *RedigoDB) Databases() int {
+	return len(database.keyspaces)
+}
+
+func (database *RedigoDB) allDatabases() []*Database {
+	return lo.Map(database.keyspaces, func(_ *keyspace, index int) *Database {
+		return database.Database(index)
+	})
+}
+
+func (database *RedigoDB) checkDatabaseIndex(index int) error {
+	if index < 0 || index >= len(database.keyspaces) {
+		return errors.Wrapf(errors.ErrorInvalidArgument, "DB index %d is out of range", index)
+	}
+	return nil
+}
+
+func (database *Database) Index() int {
+	return database.index
+}
+
+// Move moves key to the database numbered index along with its TTL. It
+// returns false when key does not exist or already exists in the target.
+func (database *Database) Move(key string, index int) (bool, error) {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	commands := []types.Command{}
+	moved, err := database.unsafeMove(key, index, func(command types.Command) {
+		commands = append(commands, command)
+	})
+
+	if len(commands) > 0 {
+		database.AddCommandsToAofBuffer(types.Command{
+			Name:      types.MULTI,
+			Commands:  commands,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+
+	return moved, err
+}
+
+func (database *Database) unsafeMove(key string, index int, record commandRecorder) (bool, error) {
+	if err := database.checkDatabaseIndex(index); err != nil {
+		return false, err
+	}
+	if index == database.index {
+		return false, errors.Wrapf(errors.ErrorInvalidArgument, "source and destination databases are the same")
+	}
+
+	// unsafeGetTtl removes a key whose expire time is reached, which unsafeGet
+	// keeps until the second after, so that the target never gets an expired key
+	if _, exists := database.unsafeGetTtl(key, record); !exists {
+		return false, nil
+	}
+	value, err := database.unsafeGet(key, record)
+	if err != nil {
+		return false, nil
+	}
+
+	target := database.Database(index)
+	if _, err := target.unsafeGet(key, record); err == nil {
+		return false, nil
+	}
+
+	commandValue, err := SerializeCommandValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().Unix()
+	expireTime, hasExpiry := database.expirationKeys[key]
+
+	database.UnsafeRemoveKey(key)
+
+	target.unsafeStoreValue(key, value)
+	target.addToIndex(key, value)
+	target.touchKey(key)
+	if hasExpiry {
+		target.expirationKeys[key] = expireTime
+	}
+
+	record(types.Command{
+		Name:      types.DELETE,
+		Db:        database.index,
+		Key:       key,
+		Value:     types.CommandValue{},
+		Timestamp: now,
+	})
+	record(types.Command{
+		Name:      types.SET,
+		Db:        index,
+		Key:       key,
+		Value:     commandValue,
+		ExpireAt:  lo.Ternary(hasExpiry, expireTime, 0),
+		Timestamp: now,
+	})
+
+	database.notifyKeyspaceEvent(pubsub.GENERIC_EVENTS, MOVE_FROM_EVENT, key)
+	target.notifyKeyspaceEvent(pubsub.GENERIC_EVENTS, MOVE_TO_EVENT, key)
+	return true, nil
+}
+
+// Flush removes every key of the database.
+func (database *Database) Flush() {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	database.unsafeFlush(database.recordCommand)
+}
+
+func (database *Database) unsafeFlush(record commandRecorder) {
+	database.unsafeTouchWatchedKeys()
+
+	for key := range database.keyMetadata {
+		database.unsafeForgetKey(key)
+	}
+
+	database.indexMutex.Lock()
+	*database.keyspace = *newKeyspace()
+	database.indexMutex.Unlock()
+
+	record(types.Command{
+		Name:      types.FLUSHDB,
+		Db:        database.index,
+		Value:     types.CommandValue{},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// FlushAll removes every key of every database.
+func (database *RedigoDB) FlushAll() {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	database.unsafeFlushAll()
+
+	database.recordCommand(types.Command{
+		Name:      types.FLUSHALL,
+		Value:     types.CommandValue{},
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (database *RedigoDB) unsafeFlushAll() {
+	for _, logicalDatabase := range database.allDatabases() {
+		logicalDatabase.unsafeFlush(func(types.Command) {})
+	}
+}
+
+// SwapDatabases exchanges the keys of two databases, the connections which
+// selected one of them see the keys of the other right away.
+func (database *RedigoDB) SwapDatabases(first int, second int) error {
+	for _, index := range []int{first, second} {
+		if err := database.checkDatabaseIndex(index); err != nil {
+			return err
+		}
+	}
+
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	database.unsafeSwapDatabases(first, second)
+
+	database.recordCommand(types.Command{
+		Name:      types.SWAPDB,
+		Db:        first,
+		Value:     types.CommandValue{},
+		Timestamp: time.Now().Unix(),
+		TargetDb:  &second,
+	})
+	return nil
+}
+
+// unsafeSwapDatabases swaps the content of the keyspaces, the watchers stay
+// on their database number and are touched before and after the swap.
+func (database *RedigoDB) unsafeSwapDatabases(first int, second int) {
+	firstDatabase, secondDatabase := database.Database(first), database.Database(second)
+
+	firstDatabase.unsafeTouchWatchedKeys()
+	secondDatabase.unsafeTouchWatchedKeys()
+
+	database.indexMutex.Lock()
+	*firstDatabase.keyspace, *secondDatabase.keyspace = *secondDatabase.keyspace, *firstDatabase.keyspace
+	database.indexMutex.Unlock()
+
+	firstDatabase.unsafeTouchWatchedKeys()
+	secondDatabase.unsafeTouchWatchedKeys()
+}
+
+// parseTargetDatabase returns the other database of a SWAPDB command.
+func (database *RedigoDB) parseTargetDatabase(command types.Command) (int, error) {
+	if command.TargetDb == nil {
+		return 0, fmt.Errorf("missing target database")
+	}
+	return *command.TargetDb, database.checkDatabaseIndex(*command.TargetDb)
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type ErrorCode string
+
+const (
+	CODE_GENERIC              ErrorCode = "ERR"       // Runtime failure without a more specific code
+	CODE_NOT_FOUND            ErrorCode = "NOTFOUND"  // The key does not exist
+	CODE_EXPIRED              ErrorCode = "EXPIRED"   // The key existed but its TTL elapsed
+	CODE_EXISTS               ErrorCode = "EXISTS"    // The key already exists
+	CODE_WRONG_TYPE           ErrorCode = "WRONGTYPE" // The value type is not supported by the operation
+	CODE_USAGE                ErrorCode = "USAGE"     // Wrong number of arguments or command used out of place
+	CODE_SYNTAX               ErrorCode = "SYNTAX"    // Malformed or out of range argument
+	CODE_UNKNOWN_COMMAND      ErrorCode = "UNKNOWN"   // The command or subcommand does not exist
+	CODE_NO_AUTH              ErrorCode = "NOAUTH"    // The connection must authenticate first
+	CODE_WRONG_PASS           ErrorCode = "WRONGPASS" // Invalid username-password pair or disabled user
+	CODE_NO_PERMISSION        ErrorCode = "NOPERM"    // The user is not allowed to run the command or access the key
+	CODE_UNSUPPORTED_PROTOCOL ErrorCode = "NOPROTO"   // Unsupported RESP protocol version
+	CODE_EXEC_ABORT           ErrorCode = "EXECABORT" // The transaction was discarded
+	CODE_OUT_OF_MEMORY        ErrorCode = "OOM"       // The write does not fit under the memory limit
+)
+
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{ErrorKeyNotFound, CODE_NOT_FOUND},
+	{ErrorKeyExpired, CODE_EXPIRED},
+	{ErrorKeyAlreadyExists, CODE_EXISTS},
+	{ErrorUnsupportedValueType, CODE_WRONG_TYPE},
+	{ErrorInvalidUsage, CODE_USAGE},
+	{ErrorInvalidArgument, CODE_SYNTAX},
+	{ErrorInvalidAclRule, CODE_SYNTAX},
+	{ErrorUnknownCommand, CODE_UNKNOWN_COMMAND},
+	{ErrorAuthenticationRequired, CODE_NO_AUTH},
+	{ErrorInvalidCredentials, CODE_WRONG_PASS},
+	{ErrorNoPermission, CODE_NO_PERMISSION},
+	{ErrorUnsupportedProtocol, CODE_UNSUPPORTED_PROTOCOL},
+	{ErrorTransactionAborted, CODE_EXEC_ABORT},
+	{ErrorOutOfMemory, CODE_OUT_OF_MEMORY},
+}
+
+// CodeOf returns the wire code of the first sentinel err wraps, or ERR.
+func CodeOf(err error) ErrorCode {
+	for _, errorCode := range errorCodes {
+		if errors.Is(err, errorCode.err) {
+			return errorCode.code
+		}
+	}
+	return CODE_GENERIC
+}
+
+// IsUsageError reports whether err was caused by the way the command was
+// written rather than by the state of the database.
+func IsUsageError(err error) bool {
+	code := CodeOf(err)
+	return code == CODE_USAGE || code == CODE_SYNTAX || code == CODE_UNKNOWN_COMMAND
+}
+
+type wrappedError struct {
+	sentinel error
+	message  string
+}
+
+func (err wrappedError) Error() string {
+	return err.message
+}
+
+func (err wrappedError) Unwrap() error {
+	return err.sentinel
+}
+
+// Wrapf formats a message that replaces the sentinel text while still
+// matching the sentinel with errors.Is, and so still carrying its code.
+func Wrapf(sentinel error, format string, arguments ...any) error {
+	return wrappedError{sentinel: sentinel, message: fmt.Sprintf(format, arguments...)}
+}
+
+// ParseError turns an error reply back into an error matching the sentinel of
+// its code, so clients can use errors.Is on what the server answered.
+func ParseError(reply string) error {
+	code, message, _ := strings.Cut(reply, " ")
+
+	for _, errorCode := range errorCodes {
+		if string(errorCode.code) == code {
+			return Wrapf(errorCode.err, "%s", message)
+		}
+	}
+	return errors.New(reply)
+}
@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		code ErrorCode
+	}{
+		{Wrapf(ErrorInvalidUsage, "Usage: GET {key}"), CODE_USAGE},
+		{Wrapf(ErrorInvalidArgument, "invalid TTL"), CODE_SYNTAX},
+		{ErrorKeyNotFound, CODE_NOT_FOUND},
+		{errors.New("disk full"), CODE_GENERIC},
+	}
+
+	for _, test := range tests {
+		if code := CodeOf(test.err); code != test.code {
+			t.Errorf("CodeOf(%v) = %s, expected %s", test.err, code, test.code)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		reply    string
+		sentinel error
+	}{
+		{"USAGE Usage: GET {key}", ErrorInvalidUsage},
+		{"SYNTAX invalid TTL", ErrorInvalidArgument},
+		{"EXPIRED failed to get value: key.expired", ErrorKeyExpired},
+	}
+
+	for _, test := range tests {
+		err := ParseError(test.reply)
+		if !errors.Is(err, test.sentinel) || CodeOf(err) != CodeOf(test.sentinel) {
+			t.Errorf("ParseError(%q) = %v, expected a %v", test.reply, err, test.sentinel)
+		}
+	}
+
+	if err := ParseError("ERR something failed"); err.Error() != "ERR something failed" {
+		t.Errorf("ParseError of a generic reply returned %v", err)
+	}
+}
@@ -6,3 +6,14 @@ var ErrorKeyNotFound = errors.New("key.notFound")
 var ErrorKeyExpired = errors.New("key.expired")
 var ErrorKeyAlreadyExists = errors.New("key.alreadyExists")
 var ErrorUnsupportedValueType = errors.New("unsupported.valueType")
+var ErrorAuthenticationRequired = errors.New("auth.required")
+var ErrorInvalidCredentials = errors.New("auth.invalidCredentials")
+var ErrorNoPermission = errors.New("acl.noPermission")
+var ErrorInvalidAclRule = errors.New("acl.invalidRule")
+var ErrorInvalidUsage = errors.New("command.invalidUsage")
+var ErrorInvalidArgument = errors.New("command.invalidArgument")
+var ErrorUnknownCommand = errors.New("command.unknown")
+var ErrorUnsupportedProtocol = errors.New("protocol.unsupported")
+var ErrorPersistenceDisabled = errors.New("persistence.disabled")
+var ErrorTransactionAborted = errors.New("transaction.aborted")
+var ErrorOutOfMemory = errors.New("memory.limitReached")
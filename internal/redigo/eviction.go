@@ -0,0 +1,241 @@
+package redigo
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"redigo/internal/pubsub"
+	"redigo/internal/redigo/errors"
+	"redigo/internal/redigo/types"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// EvictionPolicy selects the keys removed when a write would exceed the memory limit.
+type EvictionPolicy string
+
+const (
+	NO_EVICTION  EvictionPolicy = "noeviction"   // Reject the writes with an OOM error
+	ALLKEYS_LRU  EvictionPolicy = "allkeys-lru"  // Evict the least recently used keys
+	ALLKEYS_LFU  EvictionPolicy = "allkeys-lfu"  // Evict the least frequently used keys
+	VOLATILE_LRU EvictionPolicy = "volatile-lru" // Evict the least recently used keys with a TTL
+	VOLATILE_TTL EvictionPolicy = "volatile-ttl" // Evict the keys with a TTL closest to expire
+)
+
+var EVICTION_POLICIES = []EvictionPolicy{NO_EVICTION, ALLKEYS_LRU, ALLKEYS_LFU, VOLATILE_LRU, VOLATILE_TTL}
+
+const (
+	EVICTION_SAMPLES      = 5           // Keys sampled in every database to pick each evicted key
+	EVICTION_POOL_SIZE    = 16          // Best candidates kept from one eviction to the next
+	KEY_OVERHEAD          = 64          // Approximate bytes used by the map entries and the metadata of a key
+	LFU_INITIAL_FREQUENCY = 5           // Frequency of new keys, so that they are not evicted right away
+	LFU_LOG_FACTOR        = 10          // The higher, the more accesses it takes to increment the frequency
+	LFU_DECAY_PERIOD      = time.Minute // The frequency decreases by one for every period without access
+)
+
+// keyMetadata is what eviction knows about a key, protected by the store mutex.
+type keyMetadata struct {
+	size       int64 // Approximate bytes of the key and its value
+	lastAccess time.Time
+	frequency  uint8 // Logarithmic access counter, see access
+}
+
+// estimateSize approximates the memory used by key and value, without their
+// index entries and expiration, see unsafeEstimateWriteSize.
+func estimateSize(key string, value any) int64 {
+	valueSize := int64(8)
+	if text, ok := value.(string); ok {
+		valueSize = int64(len(text))
+	}
+
+	return KEY_OVERHEAD + int64(len(key)) + valueSize
+}
+
+func (metadata *keyMetadata) decayedFrequency(now time.Time) uint8 {
+	periods := int64(now.Sub(metadata.lastAccess) / LFU_DECAY_PERIOD)
+	return uint8(max(int64(metadata.frequency)-periods, 0))
+}
+
+// access decays the frequency then increments it with a probability that
+// lowers as it grows, so that 255 stands for about a million accesses.
+func (metadata *keyMetadata) access(now time.Time) {
+	frequency := metadata.decayedFrequency(now)
+
+	if frequency < 255 {
+		base := float64(max(int(frequency)-LFU_INITIAL_FREQUENCY, 0))
+		if rand.Float64() < 1/(base*LFU_LOG_FACTOR+1) {
+			frequency++
+		}
+	}
+
+	metadata.frequency = frequency
+	metadata.lastAccess = now
+}
+
+// unsafeStoreValue sets the value of key and accounts for its memory.
+func (database *Database) unsafeStoreValue(key string, value any) {
+	database.unsafeForgetKey(key)
+
+	metadata := &keyMetadata{size: estimateSize(key, value), lastAccess: time.Now(), frequency: LFU_INITIAL_FREQUENCY}
+	database.store[key] = value
+	database.keyMetadata[key] = metadata
+	database.usedMemory += metadata.size
+}
+
+// unsafeForgetKey releases the memory accounted for key.
+func (database *Database) unsafeForgetKey(key string) {
+	if metadata, exists := database.keyMetadata[key]; exists {
+		database.usedMemory -= metadata.size
+		delete(database.keyMetadata, key)
+	}
+}
+
+func (database *Database) unsafeAccessKey(key string) {
+	if metadata, exists := database.keyMetadata[key]; exists {
+		metadata.access(time.Now())
+	}
+}
+
+// unsafeResetKeyMetadata accounts for every key of a store loaded at once.
+func (database *Database) unsafeResetKeyMetadata() {
+	for key := range database.keyMetadata {
+		database.unsafeForgetKey(key)
+	}
+
+	for key, value := range database.store {
+		database.unsafeStoreValue(key, value)
+	}
+}
+
+// unsafeReserveMemory evicts keys according to the policy until size more
+// bytes fit under the memory limit, or returns ErrorOutOfMemory. A write that
+// could never fit is rejected before anything is evicted.
+func (database *RedigoDB) unsafeReserveMemory(size int64, record commandRecorder) error {
+	config := database.Config()
+	if config.MaxMemory == 0 {
+		return nil
+	}
+
+	if size > config.MaxMemory {
+		return errors.Wrapf(errors.ErrorOutOfMemory, "value of %d bytes larger than 'maxmemory'", size)
+	}
+
+	for database.unsafeCountedMemory()+size > config.MaxMemory {
+		candidate, found := database.unsafeSelectEvictionCandidate(config.MaxMemoryPolicy)
+		if !found {
+			return errors.Wrapf(errors.ErrorOutOfMemory, "command not allowed when used memory > 'maxmemory'")
+		}
+
+		candidate.database.unsafeEvict(candidate.key, record)
+	}
+
+	return nil
+}
+
+// evictionCandidate is a key sampled for eviction and its database.
+type evictionCandidate struct {
+	database *Database
+	key      string
+}
+
+// isVolatilePolicy reports whether policy only evicts keys with a TTL.
+func isVolatilePolicy(policy EvictionPolicy) bool {
+	return policy == VOLATILE_LRU || policy == VOLATILE_TTL
+}
+
+// unsafeIsEvictable reports whether candidate still exists and can be evicted
+// under policy, the keys of the pool may have been deleted since they were sampled.
+func (candidate evictionCandidate) unsafeIsEvictable(policy EvictionPolicy) bool {
+	if !lo.HasKey(candidate.database.keyMetadata, candidate.key) {
+		return false
+	}
+	return !isVolatilePolicy(policy) || lo.HasKey(candidate.database.expirationKeys, candidate.key)
+}
+
+// unsafeSelectEvictionCandidate samples EVICTION_SAMPLES keys of every
+// database, merges them with the pool of the best candidates of the previous
+// evictions and returns the best one to evict, an approximation of the exact
+// LRU, LFU or TTL order that improves as the pool fills up.
+func (database *RedigoDB) unsafeSelectEvictionCandidate(policy EvictionPolicy) (evictionCandidate, bool) {
+	if policy == NO_EVICTION {
+		return evictionCandidate{}, false
+	}
+
+	sampled := lo.FlatMap(database.allDatabases(), func(logicalDatabase *Database, _ int) []evictionCandidate {
+		var keys []string
+		if isVolatilePolicy(policy) {
+			keys = sampleKeys(logicalDatabase.expirationKeys, EVICTION_SAMPLES)
+		} else {
+			keys = sampleKeys(logicalDatabase.store, EVICTION_SAMPLES)
+		}
+
+		return lo.Map(keys, func(key string, _ int) evictionCandidate {
+			return evictionCandidate{logicalDatabase, key}
+		})
+	})
+
+	candidates := lo.UniqBy(slices.Concat(database.evictionPool, sampled), func(candidate evictionCandidate) lo.Tuple2[int, string] {
+		return lo.T2(candidate.database.index, candidate.key)
+	})
+	candidates = lo.Filter(candidates, func(candidate evictionCandidate, _ int) bool {
+		return candidate.unsafeIsEvictable(policy)
+	})
+	if len(candidates) == 0 {
+		database.evictionPool = nil
+		return evictionCandidate{}, false
+	}
+
+	now := time.Now()
+	scores := map[EvictionPolicy]func(candidate evictionCandidate) float64{
+		ALLKEYS_LRU: func(candidate evictionCandidate) float64 {
+			return float64(now.Sub(candidate.database.keyMetadata[candidate.key].lastAccess))
+		},
+		VOLATILE_LRU: func(candidate evictionCandidate) float64 {
+			return float64(now.Sub(candidate.database.keyMetadata[candidate.key].lastAccess))
+		},
+		ALLKEYS_LFU: func(candidate evictionCandidate) float64 {
+			return float64(255 - int(candidate.database.keyMetadata[candidate.key].decayedFrequency(now)))
+		},
+		VOLATILE_TTL: func(candidate evictionCandidate) float64 {
+			return -float64(candidate.database.expirationKeys[candidate.key])
+		},
+	}
+	score := scores[policy]
+
+	slices.SortStableFunc(candidates, func(first evictionCandidate, second evictionCandidate) int {
+		return cmp.Compare(score(second), score(first))
+	})
+	database.evictionPool = candidates[1:min(len(candidates), EVICTION_POOL_SIZE+1)]
+
+	return candidates[0], true
+}
+
+// sampleKeys returns up to count keys of entries. The iteration of a map starts
+// at a random position but then follows its layout, so the keys are neighbours
+// rather than independent picks, the eviction pool makes up for it over time.
+func sampleKeys[V any](entries map[string]V, count int) []string {
+	keys := make([]string, 0, count)
+	for key := range entries {
+		if len(keys) == count {
+			break
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// unsafeEvict removes key from the store and the indexes and logs its deletion.
+func (database *Database) unsafeEvict(key string, record commandRecorder) {
+	database.UnsafeRemoveKey(key)
+
+	record(types.Command{
+		Name:      "DELETE",
+		Db:        database.index,
+		Key:       key,
+		Value:     types.CommandValue{},
+		Timestamp: time.Now().Unix(),
+	})
+	database.notifyKeyspaceEvent(pubsub.EVICTED_EVENTS, EVICTED_EVENT, key)
+	database.recordEvictedKey()
+}
@@ -0,0 +1,141 @@
+package redigo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	redigoErrors "redigo/internal/redigo/errors"
+)
+
+func openEvictionDatabase(t *testing.T, maxMemory int64) *RedigoDB {
+	t.Helper()
+
+	database, err := Open(WithInMemory(), WithMaxMemory(maxMemory), WithMaxMemoryPolicy(ALLKEYS_LRU))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Shutdown(false) })
+
+	return database
+}
+
+// fillThenLimit sets keys without a memory limit, then sets the limit to the
+// memory they use so that the next write has to evict.
+func fillThenLimit(t *testing.T, database *RedigoDB, keys []string) {
+	t.Helper()
+
+	for _, key := range keys {
+		if err := database.Database(0).Set(key, "value", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := database.Reconfigure(WithMaxMemory(database.Stats().CountedMemory)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOversizedWriteEvictsNothing(t *testing.T) {
+	database := openEvictionDatabase(t, 4096)
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := database.Database(0).Set(key, "value", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := database.Database(0).Set("big", strings.Repeat("x", 5000), 0)
+	if !errors.Is(err, redigoErrors.ErrorOutOfMemory) {
+		t.Fatalf("Set of a value larger than the limit returned %v, expected ErrorOutOfMemory", err)
+	}
+
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := database.Database(0).Get(key); err != nil {
+			t.Errorf("Get(%q) after the rejected write returned %v", key, err)
+		}
+	}
+	if evicted := database.Stats().EvictedKeys; evicted != 0 {
+		t.Errorf("%d keys were evicted by the rejected write", evicted)
+	}
+}
+
+// Small keys cost far more in the indexes than their values, the limit must
+// hold with them counted.
+func TestManySmallKeysStayUnderTheLimit(t *testing.T) {
+	for _, policy := range []EvictionPolicy{ALLKEYS_LRU, NO_EVICTION} {
+		t.Run(string(policy), func(t *testing.T) {
+			const maxMemory = 256 * 1024
+
+			database := openEvictionDatabase(t, maxMemory)
+			if err := database.Reconfigure(WithMaxMemoryPolicy(policy)); err != nil {
+				t.Fatal(err)
+			}
+
+			rejected := 0
+			for i := range 5000 {
+				key := fmt.Sprintf("session:%d", i)
+				if err := database.Database(0).Set(key, "v", int64(i%2)*100); errors.Is(err, redigoErrors.ErrorOutOfMemory) {
+					rejected++
+				} else if err != nil {
+					t.Fatal(err)
+				}
+
+				if counted := database.Stats().CountedMemory; counted > maxMemory {
+					t.Fatalf("%d bytes counted after %d writes, over the limit of %d", counted, i+1, maxMemory)
+				}
+			}
+
+			stats := database.Stats()
+			if stats.EvictedKeys == 0 && rejected == 0 {
+				t.Error("5000 keys fit under the limit, expected evictions or OOM errors")
+			}
+			if stats.CountedMemory < maxMemory*9/10 {
+				t.Errorf("%d bytes counted, expected the store to be filled near the limit of %d", stats.CountedMemory, maxMemory)
+			}
+			if counted := database.MemoryStats().Counted(); counted != stats.CountedMemory {
+				t.Errorf("MEMORY STATS counts %d bytes, the memory limit %d", counted, stats.CountedMemory)
+			}
+		})
+	}
+}
+
+func TestLeastRecentlyUsedKeyIsEvicted(t *testing.T) {
+	database := openEvictionDatabase(t, 0)
+	fillThenLimit(t, database, []string{"key0", "key1", "key2", "key3"})
+
+	if _, err := database.Database(0).Get("key0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Database(0).Set("key4", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := database.Database(0).Get("key1"); !errors.Is(err, redigoErrors.ErrorKeyNotFound) {
+		t.Errorf("Get of the least recently used key returned %v, expected it to be evicted", err)
+	}
+	if _, err := database.Database(0).Get("key0"); err != nil {
+		t.Errorf("Get of the most recently used key returned %v", err)
+	}
+}
+
+func TestEvictionPoolFollowsThePolicy(t *testing.T) {
+	database := openEvictionDatabase(t, 0)
+	fillThenLimit(t, database, []string{"key0", "key1", "key2", "key3"})
+
+	if err := database.Database(0).Set("key4", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+	evicted := database.Stats().EvictedKeys
+	if err := database.Reconfigure(WithMaxMemoryPolicy(VOLATILE_TTL)); err != nil {
+		t.Fatal(err)
+	}
+
+	err := database.Database(0).Set("key5", "value", 0)
+	if !errors.Is(err, redigoErrors.ErrorOutOfMemory) {
+		t.Fatalf("Set under volatile-ttl without keys with a TTL returned %v, expected ErrorOutOfMemory", err)
+	}
+	if after := database.Stats().EvictedKeys; after != evicted {
+		t.Errorf("%d keys were evicted, the keys left in the pool have no TTL and must be kept", after-evicted)
+	}
+}
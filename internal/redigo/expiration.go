@@ -1,16 +1,21 @@
 package redigo
 
 import (
+	"redigo/internal/pubsub"
 	"redigo/internal/redigo/types"
 	"time"
 
 	"github.com/samber/lo"
 )
 
-func (database *RedigoDB) GetTtl(key string) (int64, bool) {
+func (database *Database) GetTtl(key string) (int64, bool) {
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
+	return database.unsafeGetTtl(key, database.recordCommand)
+}
+
+func (database *Database) unsafeGetTtl(key string, record commandRecorder) (int64, bool) {
 	return lo.Ternary(
 		lo.HasKey(database.store, key),
 		func() (int64, bool) {
@@ -28,7 +33,16 @@ func (database *RedigoDB) GetTtl(key string) (int64, bool) {
 							return remainingTime, true
 						},
 						func() (int64, bool) {
-							database.SafeRemoveKey(key)
+							database.UnsafeRemoveKey(key)
+
+							record(types.Command{
+								Name:      "DELETE",
+								Db:        database.index,
+								Key:       key,
+								Value:     types.CommandValue{},
+								Timestamp: now,
+							})
+							database.notifyKeyspaceEvent(pubsub.EXPIRED_EVENTS, EXPIRED_EVENT, key)
 							return -1, false
 						},
 					)()
@@ -44,9 +58,9 @@ func (database *RedigoDB) GetTtl(key string) (int64, bool) {
 	)()
 }
 
-func (database *RedigoDB) handleTtlRestoration(command types.Command) {
+func (database *Database) handleTtlRestoration(command types.Command) {
 	shouldProcess := lo.Ternary(
-		command.Ttl != nil && *command.Ttl > 0,
+		command.ExpireAt > 0 || command.Ttl != nil && *command.Ttl > 0,
 		true,
 		false,
 	)
@@ -56,7 +70,10 @@ func (database *RedigoDB) handleTtlRestoration(command types.Command) {
 	}
 
 	now := time.Now().Unix()
-	expirationTime := command.Timestamp + *command.Ttl
+	expirationTime := command.ExpireAt
+	if expirationTime == 0 {
+		expirationTime = command.Timestamp + *command.Ttl
+	}
 
 	action := lo.Ternary(
 		expirationTime > now,
@@ -71,7 +88,7 @@ func (database *RedigoDB) handleTtlRestoration(command types.Command) {
 	action()
 }
 
-func (database *RedigoDB) applyExpiration(key string, commandTimestamp, seconds int64) {
+func (database *Database) applyExpiration(key string, commandTimestamp, seconds int64) {
 	now := time.Now().Unix()
 	elapsedTime := now - commandTimestamp
 	remainingTime := seconds - elapsedTime
@@ -84,41 +101,48 @@ func (database *RedigoDB) applyExpiration(key string, commandTimestamp, seconds
 }
 
 func (database *RedigoDB) StartDataExpirationListener() {
-	ticker := time.NewTicker(database.envs.DataExpirationInterval)
-
 	cleanupHandler := func() {
 		now := time.Now().Unix()
 
 		database.storeMutex.Lock()
 
-		expiredKeys := lo.FilterMap(
-			lo.Entries(database.expirationKeys),
-			func(entry lo.Entry[string, int64], _ int) (string, bool) {
-				return entry.Key, now > entry.Value
-			},
-		)
-
-		lo.ForEach(expiredKeys, func(key string, _ int) {
-			database.SafeRemoveKey(key)
+		commands := lo.FlatMap(database.allDatabases(), func(logicalDatabase *Database, _ int) []types.Command {
+			return logicalDatabase.unsafeRemoveExpiredKeys(now)
 		})
 
 		database.storeMutex.Unlock()
-
-		commands := lo.Map(expiredKeys, func(key string, _ int) types.Command {
-			return types.Command{
-				Name:      "DELETE",
-				Key:       key,
-				Value:     types.CommandValue{},
-				Timestamp: now,
-			}
-		})
+		database.recordExpiredKeys(len(commands))
 
 		lo.ForEach(commands, func(command types.Command, _ int) {
 			database.AddCommandsToAofBuffer(command)
 		})
 	}
 
-	for range ticker.C {
-		cleanupHandler()
-	}
+	database.runTicker(func(config Config) time.Duration { return config.DataExpirationInterval }, cleanupHandler)
+}
+
+// unsafeRemoveExpiredKeys removes the keys whose TTL elapsed before now and
+// returns the commands logging their deletion.
+func (database *Database) unsafeRemoveExpiredKeys(now int64) []types.Command {
+	expiredKeys := lo.FilterMap(
+		lo.Entries(database.expirationKeys),
+		func(entry lo.Entry[string, int64], _ int) (string, bool) {
+			return entry.Key, now > entry.Value
+		},
+	)
+
+	lo.ForEach(expiredKeys, func(key string, _ int) {
+		database.UnsafeRemoveKey(key)
+		database.notifyKeyspaceEvent(pubsub.EXPIRED_EVENTS, EXPIRED_EVENT, key)
+	})
+
+	return lo.Map(expiredKeys, func(key string, _ int) types.Command {
+		return types.Command{
+			Name:      "DELETE",
+			Db:        database.index,
+			Key:       key,
+			Value:     types.CommandValue{},
+			Timestamp: now,
+		}
+	})
 }
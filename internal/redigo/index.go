@@ -3,75 +3,99 @@ package redigo
 import (
 	"fmt"
 	"os"
-	"redigo/envs"
+	"path/filepath"
 	"redigo/internal/redigo/types"
 	"redigo/pkg/utils"
 	"sync"
+	"time"
 
 	"github.com/samber/lo"
 )
 
+type RedigoDB struct {
+	keyspaces              []*keyspace     // Logical databases, selected by their number
+	storeMutex             sync.Mutex      // Protects concurrent access to the keyspaces
+	aofFile                *os.File        // Handle to the AOF for persistence
+	aofMutex               sync.Mutex      // Protects concurrent writes to AOF file
+	config                 Config          // Data directory, persistence, intervals and default TTL
+	aofCommandsBuffer      []types.Command // Buffer for AOF commands before flushing to disk
+	aofCommandsBufferMutex sync.Mutex      // Protects concurrent access to the AOF buffer
 
-var envsConfig envs.Envs
+	indexMutex sync.RWMutex // Protects concurrent access to the indexes of the keyspaces
 
-type RedigoDB struct {
-	store                  map[string]any   // Main key-value store
-	expirationKeys         map[string]int64 // Maps keys to their expiration timestamps
-	storeMutex             sync.Mutex       // Protects concurrent access to store and expirationKeys
-	aofFile                *os.File         // Handle to the AOF for persistence
-	aofMutex               sync.Mutex       // Protects concurrent writes to AOF file
-	envs                   envs.Envs        // Configuration loaded from environment variables
-	aofCommandsBuffer      []types.Command  // Buffer for AOF commands before flushing to disk
-	aofCommandsBufferMutex sync.Mutex       // Protects concurrent access to the AOF buffer
-
-	valueIndex  *types.ReverseIndex // Index for searching by exact value
-	prefixIndex *types.ReverseIndex // Index for searching by key prefix
-	suffixIndex *types.ReverseIndex // Index for searching by key suffix
-	indexMutex  sync.RWMutex        // Protects concurrent access to indexes
+	watchers map[watchedKey]map[*Watcher]bool // Transactions watching each key, protected by storeMutex
+
+	usedMemory int64 // Approximate memory of the keys and values of every keyspace, protected by storeMutex
+
+	evictionPool []evictionCandidate // Best eviction candidates kept between evictions, protected by storeMutex
+
+	lastAofFlush PersistenceStatus // Outcome of the last AOF buffer flush that wrote commands
+	lastSnapshot PersistenceStatus // Outcome of the last snapshot
+	counters     counters          // Expirations, AOF flush errors and snapshots since startup
+	statusMutex  sync.Mutex        // Protects lastAofFlush, lastSnapshot and counters
+
+	configMutex   sync.RWMutex  // Protects the settings of config changed by Reconfigure
+	configChanges chan struct{} // Closed and replaced every time Reconfigure changes config
+
+	stopBackgroundProcesses chan struct{}  // Closed to stop the snapshot, buffer and expiration listeners
+	backgroundProcesses     sync.WaitGroup // Tracks the running background listeners
+	shutdownOnce            sync.Once      // Makes Shutdown idempotent
 }
 
-func InitializeRedigo() (*RedigoDB, error) {
-	envs := envs.Gets()
+// Open creates a database configured by options, loads its data unless it is
+// in memory and starts the background listeners, stopped by Shutdown.
+func Open(options ...Option) (*RedigoDB, error) {
+	config := defaultConfig()
+	for _, option := range options {
+		option(&config)
+	}
 
-	database := &RedigoDB{
-		store:             make(map[string]any),
-		expirationKeys:    make(map[string]int64),
-		envs:              envs,
-		aofCommandsBuffer: make([]types.Command, 0),
+	if err := validateConfig(config); err != nil {
+		return nil, err
 	}
 
-	indexTypes := []types.IndexType{
-		types.VALUE_INDEX,
-		types.PREFIX_INDEX,
-		types.SUFFIX_INDEX,
+	if config.Persistence {
+		dataDirPath, err := lo.Ternary(
+			config.DataDirPath == "",
+			func() (string, error) { return utils.GetRedigoFullPath("") },
+			func() (string, error) { return config.DataDirPath, os.MkdirAll(config.DataDirPath, 0755) },
+		)()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create data directory: %w", err)
+		}
+		config.DataDirPath = dataDirPath
 	}
-	indexes := lo.Map(
-		indexTypes,
-		func(indexType types.IndexType, _ int) *types.ReverseIndex {
-			return &types.ReverseIndex{
-				Type:    indexType,
-				Entries: make(map[string]*types.IndexEntry),
-			}
-		})
 
-	database.valueIndex = indexes[0]
-	database.prefixIndex = indexes[1]
-	database.suffixIndex = indexes[2]
+	database := &RedigoDB{
+		keyspaces:         lo.Times(config.Databases, func(_ int) *keyspace { return newKeyspace() }),
+		watchers:          make(map[watchedKey]map[*Watcher]bool),
+		config:            config,
+		aofCommandsBuffer: make([]types.Command, 0),
+		configChanges:     make(chan struct{}),
+
+		stopBackgroundProcesses: make(chan struct{}),
+	}
 
+	// The indexes are loaded before the AOF replay, so that it applies the
+	// SWAPDB and FLUSHDB commands to them as well.
 	initSteps := []types.InitializationStep{
 		{
 			Name: "snapshot",
 			Function: database.LoadFromSnapshot,
 		},
 		{
-			Name: "aof_setup",
+			Name: "indexes_load",
 			Function: func() error {
-				aofPath, err := utils.GetAOFPath()
-				if err != nil {
-					return err
+				if err := database.LoadIndexesFromFile(); err != nil {
+					fmt.Printf("Failed to load indexes: %v\n", err)
 				}
-
-				loadedAOF, err := os.OpenFile(aofPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+				return nil
+			},
+		},
+		{
+			Name: "aof_setup",
+			Function: func() error {
+				loadedAOF, err := os.OpenFile(database.dataFilePath(utils.AOF_FILENAME), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 				if err != nil {
 					return err
 				}
@@ -84,40 +108,113 @@ func InitializeRedigo() (*RedigoDB, error) {
 			Name: "aof_load",
 			Function: database.LoadFromAof,
 		},
-		{
-			Name: "indexes_load",
-			Function: func() error {
-				if err := database.LoadIndexesFromFile(); err != nil {
-					fmt.Printf("Failed to load indexes: %v\n", err)
-				}
-				return nil
-			},
-		},
 	}
 
-	for _, step := range initSteps {
+	for _, step := range lo.Ternary(config.Persistence, initSteps, []types.InitializationStep{}) {
 		if err := step.Function(); err != nil {
 			return nil, fmt.Errorf("error during %s initialization: %w", step.Name, err)
 		}
 	}
 
-	backgroundProcesses := []func(){
-		database.StartSnapshotListener,
-		database.StartDataExpirationListener,
-		database.StartBufferListener,
-	}
+	backgroundProcesses := lo.Ternary(
+		config.Persistence,
+		[]func(){database.StartSnapshotListener, database.StartDataExpirationListener, database.StartBufferListener},
+		[]func(){database.StartDataExpirationListener},
+	)
 
 	lo.ForEach(
 		backgroundProcesses,
 		func(process func(), _ int) {
-			go process()
+			database.backgroundProcesses.Add(1)
+			go func() {
+				defer database.backgroundProcesses.Done()
+				process()
+			}()
 		},
 	)
 
 	return database, nil
 }
 
-func (database *RedigoDB) addToIndex(key string, value any) {
+// Shutdown stops the background listeners, flushes the AOF buffer, optionally
+// takes a final snapshot and closes the AOF file. Only the first call has an effect.
+func (database *RedigoDB) Shutdown(save bool) error {
+	var shutdownError error
+
+	database.shutdownOnce.Do(func() {
+		close(database.stopBackgroundProcesses)
+		database.backgroundProcesses.Wait()
+
+		shutdownSteps := []types.InitializationStep{
+			{
+				Name:     "aof_flush",
+				Function: database.FlushBuffer,
+			},
+			{
+				Name: "snapshot",
+				Function: func() error {
+					return lo.Ternary(save && database.config.Persistence, database.UpdateSnapshot, func() error { return nil })()
+				},
+			},
+			{
+				Name:     "aof_close",
+				Function: database.CloseAof,
+			},
+		}
+
+		for _, step := range shutdownSteps {
+			if err := step.Function(); err != nil {
+				shutdownError = fmt.Errorf("error during %s shutdown: %w", step.Name, err)
+				return
+			}
+		}
+	})
+
+	return shutdownError
+}
+
+// DefaultTtl returns the TTL in seconds of keys set without one, 0 for no expiration.
+func (database *RedigoDB) DefaultTtl() int64 {
+	database.configMutex.RLock()
+	defer database.configMutex.RUnlock()
+
+	return database.config.DefaultTtl
+}
+
+func (database *RedigoDB) IsPersistent() bool {
+	return database.config.Persistence
+}
+
+func (database *RedigoDB) dataFilePath(filename string) string {
+	return filepath.Join(database.config.DataDirPath, filename)
+}
+
+// runTicker calls handler on every tick until the background listeners are
+// stopped. The ticker is reset whenever Reconfigure changes the interval.
+func (database *RedigoDB) runTicker(interval func(config Config) time.Duration, handler func()) {
+	config, changes := database.currentConfig()
+	current := interval(config)
+
+	ticker := time.NewTicker(current)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			handler()
+		case <-changes:
+			config, changes = database.currentConfig()
+			if next := interval(config); next != current {
+				current = next
+				ticker.Reset(current)
+			}
+		case <-database.stopBackgroundProcesses:
+			return
+		}
+	}
+}
+
+func (database *Database) addToIndex(key string, value any) {
 	database.indexMutex.Lock()
 	defer database.indexMutex.Unlock()
 
@@ -165,12 +262,16 @@ func (database *RedigoDB) addToIndex(key string, value any) {
 					lo.Ternary(
 						lo.HasKey(operation.Index.Entries, indexKey),
 						func() {
+							if !operation.Index.Entries[indexKey].Keys[key] {
+								database.indexesMemory += INDEX_MEMBER_SIZE
+							}
 							operation.Index.Entries[indexKey].Keys[key] = true
 						},
 						func() {
 							operation.Index.Entries[indexKey] = &types.IndexEntry{
 								Keys: map[string]bool{key: true},
 							}
+							database.indexesMemory += INDEX_ENTRY_SIZE + INDEX_MEMBER_SIZE
 						},
 					)()
 				},
@@ -179,7 +280,7 @@ func (database *RedigoDB) addToIndex(key string, value any) {
 	)
 }
 
-func (database *RedigoDB) removeFromIndex(key string, value any) {
+func (database *Database) removeFromIndex(key string, value any) {
 	database.indexMutex.Lock()
 	defer database.indexMutex.Unlock()
 
@@ -221,12 +322,16 @@ func (database *RedigoDB) removeFromIndex(key string, value any) {
 			lo.ForEach(
 				keys,
 				func(indexKey string, _ int) {
-					if entry, exists := operation.Index.Entries[indexKey]; exists {
+					if entry, exists := operation.Index.Entries[indexKey]; exists && entry.Keys[key] {
 						delete(entry.Keys, key)
+						database.indexesMemory -= INDEX_MEMBER_SIZE
 
 						lo.Ternary(
 							len(entry.Keys) == 0,
-							func() { delete(operation.Index.Entries, indexKey) },
+							func() {
+								delete(operation.Index.Entries, indexKey)
+								database.indexesMemory -= INDEX_ENTRY_SIZE
+							},
 							func() { /* nothing */ },
 						)()
 					}
@@ -236,27 +341,26 @@ func (database *RedigoDB) removeFromIndex(key string, value any) {
 	)
 }
 
-func (database *RedigoDB) SafeRemoveKey(key string) {
+func (database *Database) SafeRemoveKey(key string) {
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
-	cleanupActions := []func(){
-		func() { delete(database.store, key) },
-		func() { delete(database.expirationKeys, key) },
-	}
-
-	lo.ForEach(
-		cleanupActions,
-		func(action func(), _ int) {
-			action()
-		},
-	)
+	database.UnsafeRemoveKey(key)
 }
 
-func (database *RedigoDB) UnsafeRemoveKey(key string) {
+// UnsafeRemoveKey removes key from the store, the expirations and the indexes,
+// releasing the memory accounted for it.
+func (database *Database) UnsafeRemoveKey(key string) {
 	cleanupActions := []func(){
+		func() {
+			if value, exists := database.store[key]; exists {
+				database.removeFromIndex(key, value)
+			}
+		},
 		func() { delete(database.store, key) },
 		func() { delete(database.expirationKeys, key) },
+		func() { database.unsafeForgetKey(key) },
+		func() { database.touchKey(key) },
 	}
 
 	lo.ForEach(
@@ -268,15 +372,8 @@ func (database *RedigoDB) UnsafeRemoveKey(key string) {
 }
 
 func (database *RedigoDB) ForceSave() error {
-	return lo.Ternary(
-		func() error {
-			return database.UpdateSnapshot()
-		}() != nil,
-		func() error {
-			return fmt.Errorf("Error creating snapshot: %w", database.UpdateSnapshot())
-		},
-		func() error {
-			return nil
-		},
-	)()
+	if err := database.UpdateSnapshot(); err != nil {
+		return fmt.Errorf("Error creating snapshot: %w", err)
+	}
+	return nil
 }
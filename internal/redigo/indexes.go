@@ -16,14 +16,26 @@ type IndexMapping struct {
 }
 
 func (database *RedigoDB) DumpIndexesToFile() error {
-	indexesPath, err := utils.GetIndexesFilePath()
-	if err != nil {
-		return fmt.Errorf("failed to get index file path: %w", err)
+	for _, logicalDatabase := range database.allDatabases() {
+		if err := logicalDatabase.dumpIndexesToFile(); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// dumpIndexesToFile writes the indexes of the database to its own file, the
+// files of the empty databases other than 0 are removed instead.
+func (database *Database) dumpIndexesToFile() error {
+	indexesPath := database.dataFilePath(utils.DatabaseFilename(utils.INDEXES_FILENAME, database.index))
 
 	database.indexMutex.RLock()
 	defer database.indexMutex.RUnlock()
 
+	if database.index != 0 && len(database.valueIndex.Entries) == 0 {
+		return removeFileIfExists(indexesPath)
+	}
+
 	indexEntries := []lo.Entry[string, *types.ReverseIndex]{
 		{
 			Key:   "value",
@@ -54,13 +66,21 @@ func (database *RedigoDB) DumpIndexesToFile() error {
 }
 
 func (database *RedigoDB) LoadIndexesFromFile() error {
-	indexesPath, err := utils.GetIndexesFilePath()
-	if err != nil {
-		return fmt.Errorf("failed to get index file path: %w", err)
+	for _, logicalDatabase := range database.allDatabases() {
+		if err := logicalDatabase.loadIndexesFromFile(); err != nil {
+			return fmt.Errorf("database %d: %w", logicalDatabase.index, err)
+		}
 	}
+	return nil
+}
+
+func (database *Database) loadIndexesFromFile() error {
+	indexesPath := database.dataFilePath(utils.DatabaseFilename(utils.INDEXES_FILENAME, database.index))
 
 	if _, err := os.Stat(indexesPath); os.IsNotExist(err) {
-		fmt.Println("No index file found, continuing with empty indexes")
+		if database.index == 0 {
+			fmt.Println("No index file found, continuing with empty indexes")
+		}
 		return nil
 	}
 
@@ -107,11 +127,21 @@ func (database *RedigoDB) LoadIndexesFromFile() error {
 			return len((*mapping.Field).Entries)
 		},
 	)
+	database.indexesMemory = lo.SumBy(indexMappings, func(mapping IndexMapping) int64 {
+		return indexMemory(*mapping.Field)
+	})
 
 	fmt.Printf(
-		"Loaded %d value indexes, %d prefix indexes, %d suffix indexes\n",
-		counts[0], counts[1], counts[2],
+		"Loaded %d value indexes, %d prefix indexes, %d suffix indexes in database %d\n",
+		counts[0], counts[1], counts[2], database.index,
 	)
 
 	return nil
 }
+
+func removeFileIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package redigo
+
+import (
+	"redigo/internal/redigo/types"
+	"redigo/pkg/utils"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// The sizes below approximate the Go runtime layout, the index keys are not
+// counted since they are substrings sharing the memory of the keys.
+const (
+	STRING_HEADER_SIZE    = 16                                         // Pointer and length of a string
+	MAP_SLOT_OVERHEAD     = 16                                         // Hash, padding and spare capacity of a map slot
+	EXPIRATION_ENTRY_SIZE = STRING_HEADER_SIZE + 8 + MAP_SLOT_OVERHEAD // A key and its timestamp in the expiration map
+	INDEX_MEMBER_SIZE     = STRING_HEADER_SIZE + 1 + MAP_SLOT_OVERHEAD // A key in the key set of an index entry
+	INDEX_ENTRY_SIZE      = 224                                        // An index entry in its index, with the header and first bucket of its key set
+	AOF_COMMAND_OVERHEAD  = 112                                        // A buffered command without its key and value
+)
+
+// MemoryStats breaks down the approximate memory used by the database.
+type MemoryStats struct {
+	Store       int64 // Keys, values and their eviction metadata
+	Expirations int64 // Expiration map
+	ValueIndex  int64
+	PrefixIndex int64
+	SuffixIndex int64
+	AofBuffer   int64 // Commands waiting to be flushed to the AOF
+}
+
+func (stats MemoryStats) Total() int64 {
+	return stats.Counted() + stats.AofBuffer
+}
+
+// Counted returns the part of the total compared to the memory limit, all but the AOF buffer.
+func (stats MemoryStats) Counted() int64 {
+	return stats.Store + stats.Expirations + stats.ValueIndex + stats.PrefixIndex + stats.SuffixIndex
+}
+
+// MemoryUsage returns the approximate bytes used by key: its value, its
+// expiration and its share of the index entries it belongs to.
+func (database *Database) MemoryUsage(key string) (int64, bool) {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	metadata, exists := database.keyMetadata[key]
+	if !exists {
+		return 0, false
+	}
+
+	usage := metadata.size
+	if expireTime, hasExpiry := database.expirationKeys[key]; hasExpiry {
+		if time.Now().Unix() > expireTime {
+			return 0, false
+		}
+		usage += EXPIRATION_ENTRY_SIZE
+	}
+
+	database.indexMutex.RLock()
+	defer database.indexMutex.RUnlock()
+
+	for _, index := range database.indexKeys(key, database.store[key]) {
+		for _, indexKey := range index.Value {
+			if entry, exists := index.Key.Entries[indexKey]; exists && entry.Keys[key] {
+				usage += INDEX_MEMBER_SIZE + INDEX_ENTRY_SIZE/int64(len(entry.Keys))
+			}
+		}
+	}
+
+	return usage, true
+}
+
+// indexKeys returns the entries of each index that key and value belong to.
+func (database *Database) indexKeys(key string, value any) []lo.Entry[*types.ReverseIndex, []string] {
+	return []lo.Entry[*types.ReverseIndex, []string]{
+		{Key: database.valueIndex, Value: []string{utils.ValueToString(value)}},
+		{Key: database.prefixIndex, Value: lo.Map(lo.Range(len(key)), func(i int, _ int) string { return key[:i+1] })},
+		{Key: database.suffixIndex, Value: lo.Map(lo.Range(len(key)), func(i int, _ int) string { return key[i:] })},
+	}
+}
+
+// unsafeEstimateWriteSize approximates the memory a new key adds, as counted
+// by MemoryStats: its value, its expiration and the index entries it creates
+// or joins.
+func (database *Database) unsafeEstimateWriteSize(key string, value any, ttl int64) int64 {
+	database.indexMutex.RLock()
+	defer database.indexMutex.RUnlock()
+
+	size := estimateSize(key, value) + lo.Ternary[int64](ttl > 0, EXPIRATION_ENTRY_SIZE, 0)
+	for _, index := range database.indexKeys(key, value) {
+		for _, indexKey := range index.Value {
+			size += lo.Ternary[int64](lo.HasKey(index.Key.Entries, indexKey), INDEX_MEMBER_SIZE, INDEX_ENTRY_SIZE+INDEX_MEMBER_SIZE)
+		}
+	}
+
+	return size
+}
+
+// unsafeCountedMemory returns the memory compared to the memory limit: the
+// keys and values, the expirations and the indexes of every keyspace.
+func (database *RedigoDB) unsafeCountedMemory() int64 {
+	return database.usedMemory + lo.SumBy(database.keyspaces, func(keyspace *keyspace) int64 {
+		return keyspace.indexesMemory + int64(len(keyspace.expirationKeys))*EXPIRATION_ENTRY_SIZE
+	})
+}
+
+func (database *RedigoDB) MemoryStats() MemoryStats {
+	var stats MemoryStats
+
+	database.storeMutex.Lock()
+	stats.Store = database.usedMemory
+	stats.Expirations = lo.SumBy(database.keyspaces, func(keyspace *keyspace) int64 {
+		return int64(len(keyspace.expirationKeys)) * EXPIRATION_ENTRY_SIZE
+	})
+	database.storeMutex.Unlock()
+
+	database.indexMutex.RLock()
+	for _, keyspace := range database.keyspaces {
+		stats.ValueIndex += indexMemory(keyspace.valueIndex)
+		stats.PrefixIndex += indexMemory(keyspace.prefixIndex)
+		stats.SuffixIndex += indexMemory(keyspace.suffixIndex)
+	}
+	database.indexMutex.RUnlock()
+
+	database.aofCommandsBufferMutex.Lock()
+	stats.AofBuffer = lo.SumBy(database.aofCommandsBuffer, aofCommandMemory)
+	database.aofCommandsBufferMutex.Unlock()
+
+	return stats
+}
+
+func indexMemory(index *types.ReverseIndex) int64 {
+	return lo.SumBy(lo.Values(index.Entries), func(entry *types.IndexEntry) int64 {
+		return INDEX_ENTRY_SIZE + int64(len(entry.Keys))*INDEX_MEMBER_SIZE
+	})
+}
+
+func aofCommandMemory(command types.Command) int64 {
+	valueSize := int64(8)
+	if text, ok := command.Value.Value.(string); ok {
+		valueSize = int64(len(text))
+	}
+
+	return AOF_COMMAND_OVERHEAD + int64(len(command.Key)) + valueSize + lo.SumBy(command.Commands, aofCommandMemory)
+}
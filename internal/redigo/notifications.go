@@ -0,0 +1,25 @@
+package redigo
+
+import "redigo/internal/pubsub"
+
+const (
+	SET_EVENT     = "set"     // A key was set
+	DEL_EVENT     = "del"     // A key was deleted
+	EXPIRE_EVENT  = "expire"  // A TTL was set on a key
+	PERSIST_EVENT = "persist" // The TTL of a key was removed
+	EXPIRED_EVENT = "expired" // A key was removed because its TTL elapsed
+	EVICTED_EVENT = "evicted" // A key was removed to stay under the memory limit
+
+	MOVE_FROM_EVENT = "move_from" // A key was moved to another database, notified in its former database
+	MOVE_TO_EVENT   = "move_to"   // A key was moved from another database, notified in its new database
+)
+
+// KeyspaceNotifier receives the events of the keys that change. It is called
+// with the store locked so it must not block nor use the database.
+type KeyspaceNotifier func(db int, class pubsub.KeyspaceEvents, event string, key string)
+
+func (database *Database) notifyKeyspaceEvent(class pubsub.KeyspaceEvents, event string, key string) {
+	if database.config.KeyspaceNotifier != nil {
+		database.config.KeyspaceNotifier(database.index, class, event, key)
+	}
+}
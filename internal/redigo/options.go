@@ -0,0 +1,166 @@
+package redigo
+
+import (
+	"fmt"
+	"redigo/pkg/utils"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+type Config struct {
+	DataDirPath            string           // Directory of the snapshot, AOF and index files, empty for ~/.redigo
+	Persistence            bool             // Whether data is loaded from and saved to DataDirPath
+	SnapshotSaveInterval   time.Duration    // Interval between two automatic snapshots
+	FlushBufferInterval    time.Duration    // Interval between two AOF buffer flushes
+	DataExpirationInterval time.Duration    // Interval between two sweeps of the expired keys
+	DefaultTtl             int64            // TTL in seconds of keys set without one, 0 for no expiration
+	KeyspaceNotifier       KeyspaceNotifier // Receives the keyspace events, nil to disable them
+	Databases              int              // Number of logical databases, selected by their number from 0
+
+	MaxMemory       int64          // Approximate memory the keys and values may use, 0 for no limit
+	MaxMemoryPolicy EvictionPolicy // Keys evicted when a write would exceed MaxMemory
+}
+
+type Option func(config *Config)
+
+func defaultConfig() Config {
+	return Config{
+		Persistence:            true,
+		SnapshotSaveInterval:   5 * time.Minute,
+		FlushBufferInterval:    10 * time.Minute,
+		DataExpirationInterval: time.Minute,
+		Databases:              16,
+		MaxMemoryPolicy:        NO_EVICTION,
+	}
+}
+
+func validateConfig(config Config) error {
+	intervals := []time.Duration{config.SnapshotSaveInterval, config.FlushBufferInterval, config.DataExpirationInterval}
+	if lo.ContainsBy(intervals, func(interval time.Duration) bool { return interval <= 0 }) {
+		return fmt.Errorf("background intervals must be positive")
+	}
+	if config.DefaultTtl < 0 {
+		return fmt.Errorf("default TTL must not be negative")
+	}
+	if config.Databases < 1 {
+		return fmt.Errorf("at least one database is required")
+	}
+	if config.MaxMemory < 0 {
+		return fmt.Errorf("max memory must not be negative")
+	}
+	if !lo.Contains(EVICTION_POLICIES, config.MaxMemoryPolicy) {
+		return fmt.Errorf("unknown eviction policy '%s'", config.MaxMemoryPolicy)
+	}
+	return nil
+}
+
+// Config returns the current configuration of the database.
+func (database *RedigoDB) Config() Config {
+	config, _ := database.currentConfig()
+	return config
+}
+
+// currentConfig returns the configuration along with a channel closed on its next change.
+func (database *RedigoDB) currentConfig() (Config, chan struct{}) {
+	database.configMutex.RLock()
+	defer database.configMutex.RUnlock()
+
+	return database.config, database.configChanges
+}
+
+// Reconfigure applies options to the running database. Only the background
+// intervals, the default TTL and the memory limit can change, the listeners
+// pick up the new intervals right away.
+func (database *RedigoDB) Reconfigure(options ...Option) error {
+	database.configMutex.Lock()
+	defer database.configMutex.Unlock()
+
+	config := database.config
+	for _, option := range options {
+		option(&config)
+	}
+
+	if err := validateConfig(config); err != nil {
+		return err
+	}
+
+	database.config.SnapshotSaveInterval = config.SnapshotSaveInterval
+	database.config.FlushBufferInterval = config.FlushBufferInterval
+	database.config.DataExpirationInterval = config.DataExpirationInterval
+	database.config.DefaultTtl = config.DefaultTtl
+	database.config.MaxMemory = config.MaxMemory
+	database.config.MaxMemoryPolicy = config.MaxMemoryPolicy
+
+	close(database.configChanges)
+	database.configChanges = make(chan struct{})
+	return nil
+}
+
+func WithDataDir(path string) Option {
+	return func(config *Config) {
+		config.DataDirPath = path
+	}
+}
+
+func WithSnapshotInterval(interval time.Duration) Option {
+	return func(config *Config) {
+		config.SnapshotSaveInterval = interval
+	}
+}
+
+func WithFlushBufferInterval(interval time.Duration) Option {
+	return func(config *Config) {
+		config.FlushBufferInterval = interval
+	}
+}
+
+func WithExpirationInterval(interval time.Duration) Option {
+	return func(config *Config) {
+		config.DataExpirationInterval = interval
+	}
+}
+
+// WithDefaultTTL sets the TTL of keys set without one, rounded up to the second.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(config *Config) {
+		config.DefaultTtl = utils.DurationToSeconds(ttl)
+	}
+}
+
+// WithMaxMemory limits the approximate memory used by the keys and values,
+// writes beyond it evict keys according to the eviction policy.
+func WithMaxMemory(bytes int64) Option {
+	return func(config *Config) {
+		config.MaxMemory = bytes
+	}
+}
+
+func WithMaxMemoryPolicy(policy EvictionPolicy) Option {
+	return func(config *Config) {
+		config.MaxMemoryPolicy = policy
+	}
+}
+
+// WithDatabases sets the number of logical databases, 16 by default.
+func WithDatabases(count int) Option {
+	return func(config *Config) {
+		config.Databases = count
+	}
+}
+
+// WithInMemory disables persistence: nothing is loaded from or written to
+// disk, the data is lost when the database is closed.
+func WithInMemory() Option {
+	return func(config *Config) {
+		config.Persistence = false
+	}
+}
+
+// WithKeyspaceNotifier calls notifier every time a key is set, deleted, gets
+// a TTL or expires.
+func WithKeyspaceNotifier(notifier KeyspaceNotifier) Option {
+	return func(config *Config) {
+		config.KeyspaceNotifier = notifier
+	}
+}
@@ -0,0 +1,180 @@
+package redigo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	redigoErrors "redigo/internal/redigo/errors"
+)
+
+// restart shuts database down without a final snapshot, so that the next
+// Open replays the AOF, and reopens the data directory.
+func restart(t *testing.T, database *RedigoDB, dataDir string) *RedigoDB {
+	t.Helper()
+
+	if err := database.Shutdown(false); err != nil {
+		t.Fatal(err)
+	}
+	return openPersistentDatabase(t, dataDir)
+}
+
+func openPersistentDatabase(t *testing.T, dataDir string) *RedigoDB {
+	t.Helper()
+
+	database, err := Open(WithDataDir(dataDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Shutdown(false) })
+
+	return database
+}
+
+// expectKeys checks which of the databases hold key after a restart.
+func expectKeys(t *testing.T, database *RedigoDB, key string, expected map[int]bool) {
+	t.Helper()
+
+	for index, exists := range expected {
+		_, err := database.Database(index).Get(key)
+		if exists && err != nil {
+			t.Errorf("Get(%q) in database %d returned %v, expected the key", key, index, err)
+		}
+		if !exists && !errors.Is(err, redigoErrors.ErrorKeyNotFound) {
+			t.Errorf("Get(%q) in database %d returned %v, expected ErrorKeyNotFound", key, index, err)
+		}
+	}
+}
+
+func TestRestartReplaysSwapDb(t *testing.T) {
+	dataDir := t.TempDir()
+	database := openPersistentDatabase(t, dataDir)
+
+	if err := database.Database(0).Set("a", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SwapDatabases(1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	database = restart(t, database, dataDir)
+	expectKeys(t, database, "a", map[int]bool{0: false, 1: true})
+}
+
+func TestRestartReplaysMove(t *testing.T) {
+	dataDir := t.TempDir()
+	database := openPersistentDatabase(t, dataDir)
+
+	if err := database.Database(0).Set("a", "value", 100); err != nil {
+		t.Fatal(err)
+	}
+	if moved, err := database.Database(0).Move("a", 1); !moved || err != nil {
+		t.Fatalf("Move returned %v, %v", moved, err)
+	}
+
+	database = restart(t, database, dataDir)
+	expectKeys(t, database, "a", map[int]bool{0: false, 1: true})
+	if ttl, _ := database.Database(1).GetTtl("a"); ttl <= 0 || ttl > 100 {
+		t.Errorf("TTL of the moved key is %d after a restart, expected at most 100", ttl)
+	}
+}
+
+func TestMoveSkipsExpiredKeys(t *testing.T) {
+	database, err := Open(WithInMemory())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Shutdown(false)
+
+	if err := database.Database(0).Set("a", "value", 100); err != nil {
+		t.Fatal(err)
+	}
+	database.storeMutex.Lock()
+	database.Database(0).expirationKeys["a"] = time.Now().Unix()
+	database.storeMutex.Unlock()
+
+	if moved, err := database.Database(0).Move("a", 1); moved || err != nil {
+		t.Errorf("Move of a key whose expire time is reached returned %v, %v, expected false", moved, err)
+	}
+	expectKeys(t, database, "a", map[int]bool{0: false, 1: false})
+}
+
+func TestRestartForgetsKeysExpiredByTtl(t *testing.T) {
+	dataDir := t.TempDir()
+	database := openPersistentDatabase(t, dataDir)
+
+	if err := database.Database(0).Set("a", "value", 100); err != nil {
+		t.Fatal(err)
+	}
+	database.storeMutex.Lock()
+	database.Database(0).expirationKeys["a"] = time.Now().Unix()
+	database.storeMutex.Unlock()
+
+	if ttl, exists := database.Database(0).GetTtl("a"); exists {
+		t.Fatalf("GetTtl of a key whose expire time is reached returned %d, expected no key", ttl)
+	}
+
+	database = restart(t, database, dataDir)
+	expectKeys(t, database, "a", map[int]bool{0: false})
+}
+
+func TestRestartReplaysFlushDb(t *testing.T) {
+	dataDir := t.TempDir()
+	database := openPersistentDatabase(t, dataDir)
+
+	for _, index := range []int{0, 1} {
+		if err := database.Database(index).Set("a", "value", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	database.Database(0).Flush()
+
+	database = restart(t, database, dataDir)
+	expectKeys(t, database, "a", map[int]bool{0: false, 1: true})
+}
+
+func TestRestartReplaysAofOverSnapshot(t *testing.T) {
+	dataDir := t.TempDir()
+	database := openPersistentDatabase(t, dataDir)
+
+	for _, key := range []string{"a", "b"} {
+		if err := database.Database(0).Set(key, "value", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := database.UpdateSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	database.Database(0).Delete("a")
+	if err := database.Database(0).Set("c", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	database = restart(t, database, dataDir)
+	expectKeys(t, database, "a", map[int]bool{0: false})
+	expectKeys(t, database, "b", map[int]bool{0: true})
+	expectKeys(t, database, "c", map[int]bool{0: true})
+}
+
+// A SWAPDB still buffered when the snapshot is taken must not be replayed on
+// top of the snapshot, which already holds the swapped databases.
+func TestSnapshotDropsBufferedCommands(t *testing.T) {
+	dataDir := t.TempDir()
+	database := openPersistentDatabase(t, dataDir)
+
+	if err := database.Database(0).Set("a", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SwapDatabases(0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.UpdateSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if length := database.Stats().AofBufferLength; length != 0 {
+		t.Errorf("%d commands are still buffered after the snapshot", length)
+	}
+
+	database = restart(t, database, dataDir)
+	expectKeys(t, database, "a", map[int]bool{0: false, 1: true})
+}
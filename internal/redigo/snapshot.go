@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"redigo/internal/redigo/errors"
 	"redigo/pkg/utils"
 	"time"
 
@@ -11,82 +12,49 @@ import (
 )
 
 func (database *RedigoDB) StartSnapshotListener() {
-	ticker := time.NewTicker(database.envs.SnapshotSaveInterval)
-
 	snapshotHandler := func() {
+		err := database.UpdateSnapshot()
 		message := lo.Ternary(
-			database.UpdateSnapshot() != nil,
-			fmt.Sprintf("Error when updating snapshot: %v", database.UpdateSnapshot()),
+			err != nil,
+			fmt.Sprintf("Error when updating snapshot: %v", err),
 			"Snapshot updated successfully",
 		)
 		fmt.Println(message)
 	}
 
-	for range ticker.C {
-		snapshotHandler()
-	}
+	database.runTicker(func(config Config) time.Duration { return config.SnapshotSaveInterval }, snapshotHandler)
 }
 
-func (database *RedigoDB) UpdateSnapshot() error {
+func (database *RedigoDB) UpdateSnapshot() (err error) {
+	if !database.config.Persistence {
+		return errors.ErrorPersistenceDisabled
+	}
+	start := time.Now()
+	defer func() { database.recordSnapshot(time.Since(start), err) }()
+
 	database.storeMutex.Lock()
 	defer database.storeMutex.Unlock()
 
 	now := time.Now().Unix()
 
-	snapshotMap := make(map[string]map[string]any)
-
-	for key, value := range database.store {
-		if expireTime, exists := database.expirationKeys[key]; exists && now > expireTime {
-			database.UnsafeRemoveKey(key)
-			continue
-		}
-
-		snapshotMap[key] = map[string]any{
-			"value": value,
-		}
-	}
-
-	jsonData, err := json.MarshalIndent(snapshotMap, "", "  ")
-	if err != nil {
-		return err
-	}
-
 	fileOperations := []struct {
 		name     string
 		function func() error
 	}{
 		{
-			"get_snapshot_path",
-			func() error {
-				_, err := utils.GetSnapshotFilePath()
-				return err
-			},
-		},
-		{
-			"write_temp_file",
+			"write_snapshots",
 			func() error {
-				snapshotPath, _ := utils.GetSnapshotFilePath()
-				tempSnapshotPath := snapshotPath + ".tmp"
-				return os.WriteFile(tempSnapshotPath, jsonData, 0644)
-			},
-		},
-		{
-			"atomic_rename",
-			func() error {
-				snapshotPath, _ := utils.GetSnapshotFilePath()
-				tempSnapshotPath := snapshotPath + ".tmp"
-				return os.Rename(tempSnapshotPath, snapshotPath)
+				for _, logicalDatabase := range database.allDatabases() {
+					if err := logicalDatabase.writeSnapshot(now); err != nil {
+						return err
+					}
+				}
+				return nil
 			},
 		},
 		{
 			"truncate_aof",
-			func() error {
-				aofPath, err := utils.GetAOFPath()
-				if err != nil {
-					return err
-				}
-				return os.Truncate(aofPath, 0)
-			},
+			database.unsafeTruncateAof,
 		},
 		{
 			"dump_indexes",
@@ -105,17 +73,78 @@ func (database *RedigoDB) UpdateSnapshot() error {
 	return nil
 }
 
-func (database *RedigoDB) LoadFromSnapshot() error {
-	snapshotPath, err := utils.GetSnapshotFilePath()
+// unsafeTruncateAof empties the AOF and drops the buffered commands, which the
+// snapshot already contains. Holding aofMutex keeps a flush in progress from
+// writing commands older than the snapshot to the truncated file.
+func (database *RedigoDB) unsafeTruncateAof() error {
+	database.aofMutex.Lock()
+	defer database.aofMutex.Unlock()
+
+	if err := os.Truncate(database.dataFilePath(utils.AOF_FILENAME), 0); err != nil {
+		return err
+	}
+
+	database.aofCommandsBufferMutex.Lock()
+	database.aofCommandsBuffer = nil
+	database.aofCommandsBufferMutex.Unlock()
+
+	return nil
+}
+
+// writeSnapshot replaces the snapshot of the database, the snapshots of the
+// empty databases other than 0 are removed instead.
+func (database *Database) writeSnapshot(now int64) error {
+	snapshotMap := make(map[string]map[string]any)
+
+	for key, value := range database.store {
+		if expireTime, exists := database.expirationKeys[key]; exists && now > expireTime {
+			database.UnsafeRemoveKey(key)
+			continue
+		}
+
+		snapshotMap[key] = map[string]any{
+			"value": value,
+		}
+	}
+
+	snapshotPath := database.dataFilePath(utils.DatabaseFilename(utils.SNAPSHOT_FILENAME, database.index))
+	if database.index != 0 && len(snapshotMap) == 0 {
+		return removeFileIfExists(snapshotPath)
+	}
+
+	jsonData, err := json.MarshalIndent(snapshotMap, "", "  ")
 	if err != nil {
 		return err
 	}
 
+	tempSnapshotPath := snapshotPath + ".tmp"
+	if err := os.WriteFile(tempSnapshotPath, jsonData, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempSnapshotPath, snapshotPath)
+}
+
+func (database *RedigoDB) LoadFromSnapshot() error {
+	for _, logicalDatabase := range database.allDatabases() {
+		if err := logicalDatabase.loadSnapshot(); err != nil {
+			return fmt.Errorf("database %d: %w", logicalDatabase.index, err)
+		}
+	}
+	return nil
+}
+
+// loadSnapshot loads the snapshot of the database, only the database 0
+// creates an empty one when it is missing.
+func (database *Database) loadSnapshot() error {
+	snapshotPath := database.dataFilePath(utils.DatabaseFilename(utils.SNAPSHOT_FILENAME, database.index))
+
+	_, statError := os.Stat(snapshotPath)
+	if os.IsNotExist(statError) && database.index != 0 {
+		return nil
+	}
+
 	createEmptyFile := lo.Ternary(
-		func() bool {
-			_, err := os.Stat(snapshotPath)
-			return os.IsNotExist(err)
-		}(),
+		os.IsNotExist(statError),
 		func() error {
 			return os.WriteFile(snapshotPath, []byte("{}"), 0600)
 		},
@@ -153,39 +182,7 @@ func (database *RedigoDB) LoadFromSnapshot() error {
 				return
 			}
 
-			database.store[key] = lo.Switch[any, any](rawValue).
-				Case(
-					func(value any) bool {
-						switch value.(type) {
-						case string, bool, int, int64:
-							return true
-						}
-						return false
-					},
-					func(value any) any {
-						return value
-					},
-				).
-				Case(
-					func(value any) bool {
-						_, ok := value.(float64)
-						return ok
-					},
-					func(val any) any {
-						value := val.(float64)
-						return lo.Ternary(
-							value == float64(int(value)),
-							float64(int(value)),
-							value,
-						)
-					},
-				).
-				Default(
-					func(value any) any {
-						fmt.Printf("Warning: unsupported value type %T for key '%s', skipping\n", value, key)
-						return nil
-					},
-				)
+			database.store[key] = restoreSnapshotValue(key, rawValue)
 		},
 	)
 
@@ -195,7 +192,18 @@ func (database *RedigoDB) LoadFromSnapshot() error {
 			return value != nil
 		},
 	)
+	database.unsafeResetKeyMetadata()
 
 	fmt.Printf("Database loaded from snapshot: %s (%d keys)\n", snapshotPath, len(database.store))
 	return nil
 }
+
+func restoreSnapshotValue(key string, rawValue any) any {
+	switch value := rawValue.(type) {
+	case string, bool, int, int64, float64:
+		return value
+	default:
+		fmt.Printf("Warning: unsupported value type %T for key '%s', skipping\n", value, key)
+		return nil
+	}
+}
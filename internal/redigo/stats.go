@@ -0,0 +1,128 @@
+package redigo
+
+import (
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// PersistenceStatus is the outcome of the last AOF flush or snapshot.
+type PersistenceStatus struct {
+	Time  time.Time // Zero until the operation ran once
+	Error error     // Nil when the operation succeeded
+}
+
+// counters are the totals since startup reported by Stats.
+type counters struct {
+	expiredKeys      int64
+	evictedKeys      int64
+	aofFlushErrors   int64
+	snapshots        int64
+	snapshotFailures int64
+	snapshotDuration time.Duration
+}
+
+// KeyspaceStats describes the keys of a logical database.
+type KeyspaceStats struct {
+	Keys         int // Number of keys in the database
+	ExpiringKeys int // Number of keys with an expiration
+}
+
+// Stats describes the content of the database at a point in time.
+type Stats struct {
+	Keys               int   // Number of keys in every database
+	ExpiringKeys       int   // Number of keys with an expiration in every database
+	UsedMemory         int64 // Approximate memory of the keys and values
+	CountedMemory      int64 // Approximate memory compared to the memory limit: keys, values, expirations and indexes
+	AofBufferLength    int   // Commands waiting to be flushed to the AOF
+	LastAofFlush       PersistenceStatus
+	LastSnapshot       PersistenceStatus
+	ValueIndexEntries  int // Distinct values in the value index
+	PrefixIndexEntries int // Distinct prefixes in the prefix index
+	SuffixIndexEntries int // Distinct suffixes in the suffix index
+
+	ExpiredKeys      int64         // Keys removed by the expiration listener since startup
+	EvictedKeys      int64         // Keys removed to stay under the memory limit since startup
+	AofFlushErrors   int64         // Failed AOF buffer flushes since startup
+	Snapshots        int64         // Snapshots taken since startup, failed ones included
+	SnapshotFailures int64         // Failed snapshots since startup
+	SnapshotDuration time.Duration // Total duration of the snapshots taken since startup
+
+	Keyspaces []KeyspaceStats // Keys of each logical database, by number
+}
+
+func (database *RedigoDB) Stats() Stats {
+	var stats Stats
+
+	database.storeMutex.Lock()
+	stats.Keyspaces = lo.Map(database.keyspaces, func(keyspace *keyspace, _ int) KeyspaceStats {
+		return KeyspaceStats{Keys: len(keyspace.store), ExpiringKeys: len(keyspace.expirationKeys)}
+	})
+	stats.UsedMemory = database.usedMemory
+	stats.CountedMemory = database.unsafeCountedMemory()
+	database.storeMutex.Unlock()
+
+	stats.Keys = lo.SumBy(stats.Keyspaces, func(keyspace KeyspaceStats) int { return keyspace.Keys })
+	stats.ExpiringKeys = lo.SumBy(stats.Keyspaces, func(keyspace KeyspaceStats) int { return keyspace.ExpiringKeys })
+
+	database.aofCommandsBufferMutex.Lock()
+	stats.AofBufferLength = len(database.aofCommandsBuffer)
+	database.aofCommandsBufferMutex.Unlock()
+
+	database.indexMutex.RLock()
+	for _, keyspace := range database.keyspaces {
+		stats.ValueIndexEntries += len(keyspace.valueIndex.Entries)
+		stats.PrefixIndexEntries += len(keyspace.prefixIndex.Entries)
+		stats.SuffixIndexEntries += len(keyspace.suffixIndex.Entries)
+	}
+	database.indexMutex.RUnlock()
+
+	database.statusMutex.Lock()
+	stats.LastAofFlush = database.lastAofFlush
+	stats.LastSnapshot = database.lastSnapshot
+	stats.ExpiredKeys = database.counters.expiredKeys
+	stats.EvictedKeys = database.counters.evictedKeys
+	stats.AofFlushErrors = database.counters.aofFlushErrors
+	stats.Snapshots = database.counters.snapshots
+	stats.SnapshotFailures = database.counters.snapshotFailures
+	stats.SnapshotDuration = database.counters.snapshotDuration
+	database.statusMutex.Unlock()
+
+	return stats
+}
+
+func (database *RedigoDB) recordAofFlush(err error) {
+	database.statusMutex.Lock()
+	defer database.statusMutex.Unlock()
+
+	database.lastAofFlush = PersistenceStatus{Time: time.Now(), Error: err}
+	if err != nil {
+		database.counters.aofFlushErrors++
+	}
+}
+
+func (database *RedigoDB) recordSnapshot(duration time.Duration, err error) {
+	database.statusMutex.Lock()
+	defer database.statusMutex.Unlock()
+
+	database.lastSnapshot = PersistenceStatus{Time: time.Now(), Error: err}
+	database.counters.snapshots++
+	database.counters.snapshotDuration += duration
+	if err != nil {
+		database.counters.snapshotFailures++
+	}
+}
+
+func (database *RedigoDB) recordExpiredKeys(count int) {
+	database.statusMutex.Lock()
+	defer database.statusMutex.Unlock()
+
+	database.counters.expiredKeys += int64(count)
+}
+
+func (database *RedigoDB) recordEvictedKey() {
+	database.statusMutex.Lock()
+	defer database.statusMutex.Unlock()
+
+	database.counters.evictedKeys++
+}
@@ -0,0 +1,198 @@
+package redigo
+
+import (
+	"redigo/internal/redigo/errors"
+	"redigo/internal/redigo/types"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// Store is the key space commands operate on: the database, or a transaction
+// running its commands with the database locked.
+type Store interface {
+	Set(key string, value any, ttl int64) error
+	Get(key string) (any, error)
+	Delete(key string) bool
+	SetExpiry(key string, seconds int64) bool
+	GetTtl(key string) (int64, bool)
+	SearchByValue(value string) []string
+	SearchByKeyPrefix(prefix string) []string
+	SearchByKeySuffix(suffix string) []string
+	SearchByKeyContains(substring string) []string
+	DefaultTtl() int64
+	Move(key string, index int) (bool, error)
+	Flush()
+}
+
+// watchedKey is a key of a logical database.
+type watchedKey struct {
+	index int
+	key   string
+}
+
+// Watcher tracks the keys a connection watches before a transaction. It is
+// marked dirty as soon as one of them is modified, deleted or expires.
+type Watcher struct {
+	keys    map[watchedKey]bool // Protected by the store mutex
+	isDirty bool                // Protected by the store mutex
+}
+
+func NewWatcher() *Watcher {
+	return &Watcher{keys: map[watchedKey]bool{}}
+}
+
+// Watch adds keys of the database to the keys watched by watcher.
+func (database *Database) Watch(watcher *Watcher, keys []string) {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	for _, key := range keys {
+		watched := watchedKey{database.index, key}
+		if _, exists := database.watchers[watched]; !exists {
+			database.watchers[watched] = map[*Watcher]bool{}
+		}
+		database.watchers[watched][watcher] = true
+		watcher.keys[watched] = true
+	}
+}
+
+// Unwatch forgets every key watched by watcher and clears its dirty flag.
+func (database *RedigoDB) Unwatch(watcher *Watcher) {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	database.unsafeUnwatch(watcher)
+}
+
+func (database *RedigoDB) unsafeUnwatch(watcher *Watcher) {
+	for key := range watcher.keys {
+		delete(database.watchers[key], watcher)
+		if len(database.watchers[key]) == 0 {
+			delete(database.watchers, key)
+		}
+	}
+
+	watcher.keys = map[watchedKey]bool{}
+	watcher.isDirty = false
+}
+
+// touchKey marks the watchers of key dirty, it is called with the store locked.
+func (database *Database) touchKey(key string) {
+	for watcher := range database.watchers[watchedKey{database.index, key}] {
+		watcher.isDirty = true
+	}
+}
+
+// unsafeTouchWatchedKeys marks dirty the watchers of the keys of the
+// database, before all of its keys are replaced at once.
+func (database *Database) unsafeTouchWatchedKeys() {
+	for watched, watchers := range database.watchers {
+		if watched.index == database.index && lo.HasKey(database.store, watched.key) {
+			for watcher := range watchers {
+				watcher.isDirty = true
+			}
+		}
+	}
+}
+
+// Transaction runs its commands with the store locked and appends their writes
+// to the AOF as a single MULTI command once they all ran.
+type Transaction struct {
+	database *RedigoDB
+	commands []types.Command
+}
+
+// RunTransaction calls run with a transaction holding the store lock, unless
+// a key watched by watcher changed, in which case ErrorTransactionAborted is
+// returned. The keys of watcher are unwatched in both cases.
+func (database *RedigoDB) RunTransaction(watcher *Watcher, run func(transaction *Transaction)) error {
+	database.storeMutex.Lock()
+	defer database.storeMutex.Unlock()
+
+	if watcher != nil {
+		isDirty := watcher.isDirty
+		database.unsafeUnwatch(watcher)
+
+		if isDirty {
+			return errors.ErrorTransactionAborted
+		}
+	}
+
+	transaction := &Transaction{database: database}
+	run(transaction)
+
+	if len(transaction.commands) > 0 {
+		database.AddCommandsToAofBuffer(types.Command{
+			Name:      types.MULTI,
+			Commands:  transaction.commands,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+
+	return nil
+}
+
+func (transaction *Transaction) record(command types.Command) {
+	transaction.commands = append(transaction.commands, command)
+}
+
+// Database returns the logical database numbered index, its commands run as
+// part of the transaction.
+func (transaction *Transaction) Database(index int) Store {
+	return &transactionDatabase{transaction: transaction, database: transaction.database.Database(index)}
+}
+
+// transactionDatabase is a database whose writes are recorded by a transaction.
+type transactionDatabase struct {
+	transaction *Transaction
+	database    *Database
+}
+
+func (store *transactionDatabase) Set(key string, value any, ttl int64) error {
+	return store.database.unsafeSet(key, value, ttl, store.transaction.record)
+}
+
+func (store *transactionDatabase) Get(key string) (any, error) {
+	return store.database.unsafeGet(key, store.transaction.record)
+}
+
+func (store *transactionDatabase) Delete(key string) bool {
+	return store.database.unsafeDelete(key, store.transaction.record)
+}
+
+func (store *transactionDatabase) SetExpiry(key string, seconds int64) bool {
+	return store.database.unsafeSetExpiry(key, seconds, store.transaction.record)
+}
+
+func (store *transactionDatabase) GetTtl(key string) (int64, bool) {
+	return store.database.unsafeGetTtl(key, store.transaction.record)
+}
+
+func (store *transactionDatabase) SearchByValue(value string) []string {
+	return store.database.SearchByValue(value)
+}
+
+func (store *transactionDatabase) SearchByKeyPrefix(prefix string) []string {
+	return store.database.SearchByKeyPrefix(prefix)
+}
+
+func (store *transactionDatabase) SearchByKeySuffix(suffix string) []string {
+	return store.database.SearchByKeySuffix(suffix)
+}
+
+func (store *transactionDatabase) SearchByKeyContains(substring string) []string {
+	return store.database.unsafeSearchByKeyContains(substring)
+}
+
+func (store *transactionDatabase) DefaultTtl() int64 {
+	return store.database.DefaultTtl()
+}
+
+func (store *transactionDatabase) Move(key string, index int) (bool, error) {
+	return store.database.unsafeMove(key, index, store.transaction.record)
+}
+
+func (store *transactionDatabase) Flush() {
+	store.database.unsafeFlush(store.transaction.record)
+}
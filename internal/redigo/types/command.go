@@ -3,9 +3,13 @@ package types
 type CommandName string
 
 const (
-	SET    CommandName = "SET"
-	DELETE CommandName = "DELETE"
-	EXPIRE CommandName = "EXPIRE"
+	SET      CommandName = "SET"
+	DELETE   CommandName = "DELETE"
+	EXPIRE   CommandName = "EXPIRE"
+	MULTI    CommandName = "MULTI"    // Transaction, its commands are applied together
+	FLUSHDB  CommandName = "FLUSHDB"  // Removes every key of the database Db
+	FLUSHALL CommandName = "FLUSHALL" // Removes every key of every database
+	SWAPDB   CommandName = "SWAPDB"   // Swaps the database Db with the database TargetDb
 )
 
 type CommandValue struct {
@@ -15,8 +19,13 @@ type CommandValue struct {
 
 type Command struct {
 	Name      CommandName  `json:"name"`
+	Db        int          `json:"db,omitempty"` // Logical database of the key, omitted for 0
 	Key       string       `json:"key"`
 	Value     CommandValue `json:"value"`
 	Ttl       *int64       `json:"ttl,omitempty"`
 	Timestamp int64        `json:"timestamp"`
+	Commands  []Command    `json:"commands,omitempty"` // Commands of a MULTI transaction
+
+	ExpireAt int64 `json:"expire_at,omitempty"` // Unix time the key expires at, used instead of Ttl when set
+	TargetDb *int  `json:"target_db,omitempty"` // Other database of a SWAPDB
 }
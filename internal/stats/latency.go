@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// LATENCY_BUCKETS are the upper bounds of the latency histograms, a last
+// implicit bucket counts the calls slower than all of them.
+var LATENCY_BUCKETS = []time.Duration{
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+type CommandLatency struct {
+	Name          string
+	Calls         int64
+	FailedCalls   int64 // Calls answered with an error
+	TotalDuration time.Duration
+	Buckets       []int64 // Calls per bucket of LATENCY_BUCKETS, not cumulative, plus the calls slower than the last one
+}
+
+// CumulativeBuckets returns, for each bound of LATENCY_BUCKETS, the number of
+// calls that took at most that long.
+func (latency CommandLatency) CumulativeBuckets() []int64 {
+	cumulative := make([]int64, len(LATENCY_BUCKETS))
+
+	var total int64
+	for index := range LATENCY_BUCKETS {
+		total += latency.Buckets[index]
+		cumulative[index] = total
+	}
+	return cumulative
+}
+
+// CommandLatencies counts the calls, the failures and the latencies of every command.
+type CommandLatencies struct {
+	commands map[string]*CommandLatency
+	mutex    sync.Mutex
+}
+
+func NewCommandLatencies() *CommandLatencies {
+	return &CommandLatencies{commands: map[string]*CommandLatency{}}
+}
+
+func (latencies *CommandLatencies) Record(name string, duration time.Duration, failed bool) {
+	bucket, _ := slices.BinarySearch(LATENCY_BUCKETS, duration)
+
+	latencies.mutex.Lock()
+	defer latencies.mutex.Unlock()
+
+	latency, exists := latencies.commands[name]
+	if !exists {
+		latency = &CommandLatency{Name: name, Buckets: make([]int64, len(LATENCY_BUCKETS)+1)}
+		latencies.commands[name] = latency
+	}
+
+	latency.Calls++
+	if failed {
+		latency.FailedCalls++
+	}
+	latency.TotalDuration += duration
+	latency.Buckets[bucket]++
+}
+
+// Get returns a copy of the latencies of the given commands that were called
+// at least once, or of every command when names is empty, ordered by name.
+func (latencies *CommandLatencies) Get(names []string) []CommandLatency {
+	latencies.mutex.Lock()
+	defer latencies.mutex.Unlock()
+
+	if len(names) == 0 {
+		names = lo.Keys(latencies.commands)
+	}
+	names = lo.Uniq(names)
+	slices.Sort(names)
+
+	return lo.FilterMap(names, func(name string, _ int) (CommandLatency, bool) {
+		latency, exists := latencies.commands[name]
+		if !exists {
+			return CommandLatency{}, false
+		}
+
+		copied := *latency
+		copied.Buckets = slices.Clone(latency.Buckets)
+		return copied, true
+	})
+}
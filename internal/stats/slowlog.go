@@ -0,0 +1,112 @@
+package stats
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	SLOWLOG_MAX_ARGUMENTS       = 32  // Arguments kept per entry, the last one counts the others
+	SLOWLOG_MAX_ARGUMENT_LENGTH = 128 // Bytes kept per argument
+)
+
+type SlowLogEntry struct {
+	Id         int64
+	Timestamp  time.Time
+	Duration   time.Duration
+	Arguments  []string
+	Address    string // Address of the client that sent the command
+	ClientName string
+}
+
+// SlowLog keeps the most recent commands that ran longer than its threshold
+// in a fixed size ring buffer.
+type SlowLog struct {
+	entries   []SlowLogEntry // Ring buffer, next is the position of the oldest entry once it is full
+	next      int
+	length    int
+	lastId    int64
+	threshold time.Duration // Negative to disable the log, 0 to log every command
+	mutex     sync.Mutex
+}
+
+func NewSlowLog(maxLength int, threshold time.Duration) *SlowLog {
+	return &SlowLog{
+		entries:   make([]SlowLogEntry, max(maxLength, 0)),
+		threshold: threshold,
+	}
+}
+
+// Record adds the command to the log when duration reaches the threshold.
+func (slowLog *SlowLog) Record(duration time.Duration, arguments []string, address string, clientName string) {
+	if slowLog.threshold < 0 || duration < slowLog.threshold || len(slowLog.entries) == 0 {
+		return
+	}
+
+	slowLog.mutex.Lock()
+	defer slowLog.mutex.Unlock()
+
+	slowLog.lastId++
+	slowLog.entries[slowLog.next] = SlowLogEntry{
+		Id:         slowLog.lastId,
+		Timestamp:  time.Now(),
+		Duration:   duration,
+		Arguments:  truncateArguments(arguments),
+		Address:    address,
+		ClientName: clientName,
+	}
+	slowLog.next = (slowLog.next + 1) % len(slowLog.entries)
+	slowLog.length = min(slowLog.length+1, len(slowLog.entries))
+}
+
+// Get returns up to count entries, the most recent first, or every entry when count is negative.
+func (slowLog *SlowLog) Get(count int) []SlowLogEntry {
+	slowLog.mutex.Lock()
+	defer slowLog.mutex.Unlock()
+
+	if count < 0 || count > slowLog.length {
+		count = slowLog.length
+	}
+
+	entries := make([]SlowLogEntry, 0, count)
+	for index := 1; index <= count; index++ {
+		position := (slowLog.next - index + len(slowLog.entries)) % len(slowLog.entries)
+		entries = append(entries, slowLog.entries[position])
+	}
+	return entries
+}
+
+func (slowLog *SlowLog) Len() int {
+	slowLog.mutex.Lock()
+	defer slowLog.mutex.Unlock()
+
+	return slowLog.length
+}
+
+// Reset empties the log, entry ids keep increasing.
+func (slowLog *SlowLog) Reset() {
+	slowLog.mutex.Lock()
+	defer slowLog.mutex.Unlock()
+
+	clear(slowLog.entries)
+	slowLog.next = 0
+	slowLog.length = 0
+}
+
+func truncateArguments(arguments []string) []string {
+	truncated := make([]string, 0, min(len(arguments), SLOWLOG_MAX_ARGUMENTS))
+
+	for index, argument := range arguments {
+		if index == SLOWLOG_MAX_ARGUMENTS-1 && len(arguments) > SLOWLOG_MAX_ARGUMENTS {
+			truncated = append(truncated, fmt.Sprintf("... (%d more arguments)", len(arguments)-index))
+			break
+		}
+
+		if len(argument) > SLOWLOG_MAX_ARGUMENT_LENGTH {
+			argument = fmt.Sprintf("%s... (%d more bytes)", argument[:SLOWLOG_MAX_ARGUMENT_LENGTH], len(argument)-SLOWLOG_MAX_ARGUMENT_LENGTH)
+		}
+		truncated = append(truncated, argument)
+	}
+	return truncated
+}
@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"time"
+
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+type Options struct {
+	Network             string        // "tcp" or "unix", defaults to "tcp"
+	Address             string        // host:port or socket path, defaults to "localhost:6379"
+	Username            string        // ACL user, "default" when empty
+	Password            string        // Sent with AUTH on every new connection when not empty
+	Database            int           // Selected with SELECT on every new connection when not 0
+	TLSConfig           *tls.Config   // Enables TLS when not nil
+	PoolSize            int           // Maximum number of connections in use at once, defaults to 10
+	DialTimeout         time.Duration // Timeout to connect and authenticate, defaults to 5s
+	Timeout             time.Duration // Timeout of a request when the context has no deadline, defaults to 5s
+	HealthCheckInterval time.Duration // Idle connections unused for longer are pinged before reuse, defaults to 30s
+	MaxRetries          int           // Retries after a network error, defaults to 3, negative to disable
+	MinRetryBackoff     time.Duration // Delay before the first retry, doubled on each retry, defaults to 8ms
+	MaxRetryBackoff     time.Duration // Upper bound of the retry delay, defaults to 512ms
+}
+
+type Client struct {
+	options Options
+	pool    *pool
+}
+
+// New creates a client, connections are opened lazily on the first request.
+func New(options Options) *Client {
+	options.Network = lo.CoalesceOrEmpty(options.Network, "tcp")
+	options.Address = lo.CoalesceOrEmpty(options.Address, "localhost:6379")
+	options.PoolSize = lo.Ternary(options.PoolSize > 0, options.PoolSize, 10)
+	options.DialTimeout = lo.CoalesceOrEmpty(options.DialTimeout, 5*time.Second)
+	options.Timeout = lo.CoalesceOrEmpty(options.Timeout, 5*time.Second)
+	options.HealthCheckInterval = lo.CoalesceOrEmpty(options.HealthCheckInterval, 30*time.Second)
+	options.MaxRetries = lo.Ternary(options.MaxRetries == 0, 3, max(options.MaxRetries, 0))
+	options.MinRetryBackoff = lo.CoalesceOrEmpty(options.MinRetryBackoff, 8*time.Millisecond)
+	options.MaxRetryBackoff = lo.CoalesceOrEmpty(options.MaxRetryBackoff, 512*time.Millisecond)
+
+	return &Client{
+		options: options,
+		pool:    newPool(options),
+	}
+}
+
+// Close closes the pooled connections, requests made afterwards fail with ErrorClientClosed.
+func (client *Client) Close() error {
+	return client.pool.close()
+}
+
+// Do sends a raw command and returns its reply, error replies are returned as
+// errors matching the sentinel of their code.
+func (client *Client) Do(ctx context.Context, arguments ...string) (resp.Value, error) {
+	replies, err := client.execute(ctx, [][]string{arguments})
+	if err != nil {
+		return resp.Value{}, err
+	}
+	return replies[0], replyError(replies[0])
+}
+
+// execute runs the commands as one pipeline on a pooled connection, retrying
+// with exponential backoff when the connection fails before the commands were
+// fully written. Error replies are not retried, they are returned as values.
+func (client *Client) execute(ctx context.Context, commands [][]string) ([]resp.Value, error) {
+	for attempt := 0; ; attempt++ {
+		replies, err := client.executeOnce(ctx, commands)
+		if err == nil {
+			return replies, nil
+		}
+
+		if attempt >= client.options.MaxRetries || !isRetryable(ctx, err) {
+			return nil, err
+		}
+
+		select {
+		case <-time.After(client.retryBackoff(attempt)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (client *Client) executeOnce(ctx context.Context, commands [][]string) ([]resp.Value, error) {
+	connection, err := client.pool.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := connection.roundTrip(ctx, commands, client.options.Timeout)
+	client.pool.put(connection, err != nil || isDirty(commands))
+	return replies, err
+}
+
+// Commands leaving a connection in a state other requests must not inherit:
+// pushed messages, another user, database or protocol, or an open transaction.
+var dirtyCommands = []string{"SUBSCRIBE", "PSUBSCRIBE", "MONITOR", "AUTH", "SELECT", "HELLO", "MULTI", "WATCH"}
+
+// isDirty reports whether the connection must be closed rather than pooled once commands ran.
+func isDirty(commands [][]string) bool {
+	return lo.ContainsBy(commands, func(arguments []string) bool {
+		return len(arguments) > 0 && lo.Contains(dirtyCommands, strings.ToUpper(arguments[0]))
+	})
+}
+
+// retryBackoff doubles the delay on each attempt up to MaxRetryBackoff, with
+// jitter so that clients failing together do not retry together.
+func (client *Client) retryBackoff(attempt int) time.Duration {
+	backoff := min(client.options.MinRetryBackoff<<min(attempt, 16), client.options.MaxRetryBackoff)
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// isRetryable reports whether err is a network failure worth retrying on a new
+// connection: dial failures and writes that did not complete. Once written, a
+// command may have run, retrying it could apply a write twice.
+func isRetryable(ctx context.Context, err error) bool {
+	var networkError net.Error
+	if ctx.Err() != nil || errors.As(err, &sentError{}) {
+		return false
+	}
+	return errors.As(err, &networkError) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func replyError(reply resp.Value) error {
+	if reply.IsError() {
+		return redigoErrors.ParseError(reply.Str)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+// fakeServer answers the commands of each accepted connection with reply, it
+// closes the connection instead when reply returns false.
+type fakeServer struct {
+	listener    net.Listener
+	commands    [][]string
+	connections int
+	mutex       sync.Mutex
+}
+
+func newFakeServer(t *testing.T, reply func(connection int, arguments []string) (resp.Value, bool)) *fakeServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeServer{listener: listener}
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			server.mutex.Lock()
+			server.connections++
+			index := server.connections
+			server.mutex.Unlock()
+
+			go server.serve(connection, index, reply)
+		}
+	}()
+	return server
+}
+
+func (server *fakeServer) serve(connection net.Conn, index int, reply func(int, []string) (resp.Value, bool)) {
+	defer connection.Close()
+
+	reader, writer := resp.NewReader(connection), resp.NewWriter(connection)
+	for {
+		arguments, _, err := reader.ReadCommand()
+		if err != nil {
+			return
+		}
+
+		server.mutex.Lock()
+		server.commands = append(server.commands, arguments)
+		server.mutex.Unlock()
+
+		value, ok := reply(index, arguments)
+		if !ok {
+			return
+		}
+		writer.WriteValue(value)
+		writer.Flush()
+	}
+}
+
+func (server *fakeServer) received() ([][]string, int) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.commands, server.connections
+}
+
+func newTestClient(server *fakeServer, options Options) *Client {
+	options.Address = server.listener.Addr().String()
+	options.MinRetryBackoff = time.Millisecond
+	options.MaxRetryBackoff = time.Millisecond
+	return New(options)
+}
+
+func TestWrittenCommandsAreNotRetried(t *testing.T) {
+	server := newFakeServer(t, func(_ int, arguments []string) (resp.Value, bool) {
+		return resp.NewSimpleString("OK"), arguments[0] != "SET"
+	})
+	client := newTestClient(server, Options{})
+	defer client.Close()
+
+	if err := client.Set(context.Background(), "a", "1"); err == nil {
+		t.Fatal("SET succeeded without a reply")
+	}
+
+	commands, _ := server.received()
+	if sets := lo.CountBy(commands, func(arguments []string) bool { return arguments[0] == "SET" }); sets != 1 {
+		t.Errorf("SET was sent %d times, expected once", sets)
+	}
+}
+
+func TestDialFailuresAreRetried(t *testing.T) {
+	server := newFakeServer(t, func(connection int, arguments []string) (resp.Value, bool) {
+		if connection == 1 {
+			return resp.Value{}, false
+		}
+		return resp.NewSimpleString("OK"), true
+	})
+	client := newTestClient(server, Options{Password: "secret"})
+	defer client.Close()
+
+	if err := client.Set(context.Background(), "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, connections := server.received()
+	if connections != 2 || len(commands) != 4 || commands[3][0] != "SET" {
+		t.Errorf("received %q on %d connections, expected AUTH, then AUTH, CLIENT and SET on 2", commands, connections)
+	}
+}
+
+func TestDirtyConnectionsAreNotPooled(t *testing.T) {
+	server := newFakeServer(t, func(_ int, arguments []string) (resp.Value, bool) {
+		if arguments[0] == "SUBSCRIBE" {
+			return resp.NewPush([]resp.Value{resp.NewBulkString("subscribe"), resp.NewBulkString(arguments[1]), resp.NewInteger(1)}), true
+		}
+		return resp.NewSimpleString("PONG"), true
+	})
+	client := newTestClient(server, Options{})
+	defer client.Close()
+
+	ctx := context.Background()
+	for _, arguments := range [][]string{{"PING"}, {"PING"}, {"SUBSCRIBE", "news"}, {"PING"}} {
+		if _, err := client.Do(ctx, arguments...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, connections := server.received(); connections != 2 {
+		t.Errorf("commands ran on %d connections, expected the subscribed one to be replaced", connections)
+	}
+}
+
+func TestGetErrorCodes(t *testing.T) {
+	server := newFakeServer(t, func(_ int, arguments []string) (resp.Value, bool) {
+		switch {
+		case arguments[0] != "GET":
+			return resp.NewSimpleString("OK"), true
+		case arguments[1] == "expired":
+			return resp.NewError("EXPIRED failed to get value: key.expired"), true
+		case arguments[1] == "missing":
+			return resp.NewError("NOTFOUND failed to get value: key.notFound"), true
+		default:
+			return resp.NewNull(), true
+		}
+	})
+	client := newTestClient(server, Options{})
+	defer client.Close()
+
+	tests := map[string]error{"expired": ErrorKeyExpired, "missing": ErrorKeyNotFound, "null": ErrorKeyNotFound}
+	for key, expected := range tests {
+		if _, err := client.Get(context.Background(), key); !errors.Is(err, expected) {
+			t.Errorf("Get(%q) returned %v, expected %v", key, err, expected)
+		}
+	}
+
+	commands, _ := server.received()
+	if !slices.Equal(commands[0], []string{"CLIENT", "NILERRORS", "ON"}) {
+		t.Errorf("connection set up with %q", commands[0])
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"redigo/pkg/resp"
+	"redigo/pkg/utils"
+)
+
+// NO_EXPIRATION is the TTL of keys that never expire.
+const NO_EXPIRATION time.Duration = -1
+
+func (client *Client) Ping(ctx context.Context) error {
+	_, err := client.Do(ctx, "PING")
+	return err
+}
+
+// Set stores a new key with the default TTL of the server. Existing keys are
+// not overwritten, the server answers ErrorKeyAlreadyExists.
+func (client *Client) Set(ctx context.Context, key, value string) error {
+	_, err := client.Do(ctx, "SET", key, value)
+	return err
+}
+
+// SetWithTTL stores a new key that expires after ttl, rounded up to the
+// second, or never when ttl is 0.
+func (client *Client) SetWithTTL(ctx context.Context, key, value string, ttl time.Duration) error {
+	_, err := client.Do(ctx, "SET", key, value, formatSeconds(ttl))
+	return err
+}
+
+// Get returns ErrorKeyNotFound when the key does not exist and ErrorKeyExpired
+// when its TTL elapsed since the last sweep of the expired keys.
+func (client *Client) Get(ctx context.Context, key string) (string, error) {
+	reply, err := client.Do(ctx, "GET", key)
+	if err != nil {
+		return "", err
+	}
+
+	if reply.IsNull() {
+		return "", fmt.Errorf("failed to get value: %w", ErrorKeyNotFound)
+	}
+	return stringReply(reply)
+}
+
+// Delete reports whether the key existed.
+func (client *Client) Delete(ctx context.Context, key string) (bool, error) {
+	return booleanReply(client.Do(ctx, "DELETE", key))
+}
+
+// Expire reports whether the key existed and now expires after ttl, rounded up to the second.
+func (client *Client) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return booleanReply(client.Do(ctx, "EXPIRE", key, formatSeconds(ttl)))
+}
+
+// TTL returns the remaining time to live of the key, NO_EXPIRATION when it
+// never expires and ErrorKeyNotFound when it does not exist.
+func (client *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	seconds, err := integerReply(client.Do(ctx, "TTL", key))
+	if err != nil {
+		return 0, err
+	}
+
+	switch seconds {
+	case -2:
+		return 0, fmt.Errorf("failed to get TTL: %w", ErrorKeyNotFound)
+	case -1:
+		return NO_EXPIRATION, nil
+	default:
+		return time.Duration(seconds) * time.Second, nil
+	}
+}
+
+func (client *Client) SearchByValue(ctx context.Context, value string) ([]string, error) {
+	return stringsReply(client.Do(ctx, "SEARCHVALUE", value))
+}
+
+func (client *Client) SearchByPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return stringsReply(client.Do(ctx, "SEARCHPREFIX", prefix))
+}
+
+func (client *Client) SearchBySuffix(ctx context.Context, suffix string) ([]string, error) {
+	return stringsReply(client.Do(ctx, "SEARCHSUFFIX", suffix))
+}
+
+func (client *Client) SearchByContains(ctx context.Context, substring string) ([]string, error) {
+	return stringsReply(client.Do(ctx, "SEARCHCONTAINS", substring))
+}
+
+func (client *Client) Save(ctx context.Context) error {
+	_, err := client.Do(ctx, "SAVE")
+	return err
+}
+
+func (client *Client) BgSave(ctx context.Context) error {
+	_, err := client.Do(ctx, "BGSAVE")
+	return err
+}
+
+func formatSeconds(duration time.Duration) string {
+	return strconv.FormatInt(max(utils.DurationToSeconds(duration), 0), 10)
+}
+
+func stringReply(reply resp.Value) (string, error) {
+	switch reply.Type {
+	case resp.BULK_STRING_TYPE, resp.SIMPLE_STRING_TYPE:
+		return reply.Str, nil
+	default:
+		return "", fmt.Errorf("%w: expected a string, got '%c'", ErrorUnexpectedReply, reply.Type)
+	}
+}
+
+func integerReply(reply resp.Value, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	if reply.Type != resp.INTEGER_TYPE {
+		return 0, fmt.Errorf("%w: expected an integer, got '%c'", ErrorUnexpectedReply, reply.Type)
+	}
+	return reply.Int, nil
+}
+
+func booleanReply(reply resp.Value, err error) (bool, error) {
+	value, err := integerReply(reply, err)
+	return value == 1, err
+}
+
+func stringsReply(reply resp.Value, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.Type != resp.ARRAY_TYPE {
+		return nil, fmt.Errorf("%w: expected an array, got '%c'", ErrorUnexpectedReply, reply.Type)
+	}
+
+	values := make([]string, 0, len(reply.Array))
+	for _, element := range reply.Array {
+		value, err := stringReply(element)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"redigo/pkg/resp"
+)
+
+// Conn is a connection of its own, outside of the pool, for sessions where a
+// command depends on the state left by the previous ones: SELECT, AUTH or
+// MULTI. It is not safe for concurrent use.
+type Conn struct {
+	client     *Client
+	options    Options     // Options of the client, with the user and database switched to by the session
+	connection *connection // Nil until the first command and after a network failure
+}
+
+// Conn returns a dedicated connection, dialed on the first command. When it
+// breaks, the next command dials again and authenticates as the user and
+// selects the database the session last switched to, an open transaction
+// or subscription is lost.
+func (client *Client) Conn() *Conn {
+	return &Conn{client: client, options: client.options}
+}
+
+// Do sends a raw command on the connection and returns its reply, error
+// replies are returned as errors matching the sentinel of their code.
+func (conn *Conn) Do(ctx context.Context, arguments ...string) (resp.Value, error) {
+	if conn.connection == nil {
+		connection, err := conn.client.pool.dialAs(ctx, conn.options)
+		if err != nil {
+			return resp.Value{}, err
+		}
+		conn.connection = connection
+	}
+
+	replies, err := conn.connection.roundTrip(ctx, [][]string{arguments}, conn.options.Timeout)
+	if err != nil {
+		conn.Close()
+		var failure sentError
+		if errors.As(err, &failure) {
+			return resp.Value{}, failure.err
+		}
+		return resp.Value{}, err
+	}
+
+	if !replies[0].IsError() {
+		conn.remember(arguments)
+	}
+	return replies[0], replyError(replies[0])
+}
+
+// remember keeps the user and database a successful command switched to, so
+// that a new connection is set up the same way.
+func (conn *Conn) remember(arguments []string) {
+	switch {
+	case strings.EqualFold(arguments[0], "SELECT") && len(arguments) == 2:
+		if database, err := strconv.Atoi(arguments[1]); err == nil {
+			conn.options.Database = database
+		}
+	case strings.EqualFold(arguments[0], "AUTH") && len(arguments) == 2:
+		conn.options.Username, conn.options.Password = "", arguments[1]
+	case strings.EqualFold(arguments[0], "AUTH") && len(arguments) == 3:
+		conn.options.Username, conn.options.Password = arguments[1], arguments[2]
+	}
+}
+
+// Close closes the connection, the next command dials a new one.
+func (conn *Conn) Close() error {
+	if conn.connection == nil {
+		return nil
+	}
+
+	err := conn.connection.close()
+	conn.connection = nil
+	return err
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"redigo/pkg/resp"
+)
+
+func TestConnKeepsItsDatabaseAcrossReconnections(t *testing.T) {
+	server := newFakeServer(t, func(_ int, arguments []string) (resp.Value, bool) {
+		return resp.NewSimpleString("OK"), arguments[0] != "DEBUG"
+	})
+	client := newTestClient(server, Options{})
+	defer client.Close()
+
+	session := client.Conn()
+	defer session.Close()
+
+	ctx := context.Background()
+	for _, arguments := range [][]string{{"SELECT", "2"}, {"MULTI"}, {"SET", "a", "1"}} {
+		if _, err := session.Do(ctx, arguments...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := session.Do(ctx, "DEBUG", "RESTART"); err == nil {
+		t.Fatal("DEBUG succeeded on a closed connection")
+	}
+	if _, err := session.Do(ctx, "GET", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	commands, connections := server.received()
+	expected := [][]string{
+		{"CLIENT", "NILERRORS", "ON"}, {"SELECT", "2"}, {"MULTI"}, {"SET", "a", "1"}, {"DEBUG", "RESTART"},
+		{"CLIENT", "NILERRORS", "ON"}, {"SELECT", "2"}, {"GET", "a"},
+	}
+	if connections != 2 || !reflect.DeepEqual(commands, expected) {
+		t.Errorf("received %q on %d connections, expected %q on 2", commands, connections, expected)
+	}
+}
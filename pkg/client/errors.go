@@ -0,0 +1,28 @@
+package client
+
+import (
+	"errors"
+
+	redigoErrors "redigo/internal/redigo/errors"
+)
+
+// Sentinel errors of the server, re-exported because internal packages cannot
+// be imported outside of this module. Error replies are matched against them
+// by code, so errors.Is works the same way on both sides.
+var ErrorKeyNotFound = redigoErrors.ErrorKeyNotFound
+var ErrorKeyExpired = redigoErrors.ErrorKeyExpired
+var ErrorKeyAlreadyExists = redigoErrors.ErrorKeyAlreadyExists
+var ErrorUnsupportedValueType = redigoErrors.ErrorUnsupportedValueType
+var ErrorAuthenticationRequired = redigoErrors.ErrorAuthenticationRequired
+var ErrorInvalidCredentials = redigoErrors.ErrorInvalidCredentials
+var ErrorNoPermission = redigoErrors.ErrorNoPermission
+var ErrorInvalidAclRule = redigoErrors.ErrorInvalidAclRule
+var ErrorInvalidUsage = redigoErrors.ErrorInvalidUsage
+var ErrorInvalidArgument = redigoErrors.ErrorInvalidArgument
+var ErrorUnknownCommand = redigoErrors.ErrorUnknownCommand
+var ErrorUnsupportedProtocol = redigoErrors.ErrorUnsupportedProtocol
+var ErrorTransactionAborted = redigoErrors.ErrorTransactionAborted
+var ErrorOutOfMemory = redigoErrors.ErrorOutOfMemory
+
+var ErrorClientClosed = errors.New("client.closed")
+var ErrorUnexpectedReply = errors.New("client.unexpectedReply")
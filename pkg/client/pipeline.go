@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+type PipelineResult struct {
+	Reply resp.Value
+	Err   error // Error reply of the command, matching the sentinel of its code
+}
+
+// Pipeline queues commands and sends them in a single write, the replies are
+// read back in order. It is not safe for concurrent use.
+type Pipeline struct {
+	client   *Client
+	commands [][]string
+}
+
+func (client *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: client, commands: [][]string{}}
+}
+
+func (pipeline *Pipeline) Do(arguments ...string) *Pipeline {
+	pipeline.commands = append(pipeline.commands, arguments)
+	return pipeline
+}
+
+func (pipeline *Pipeline) Len() int {
+	return len(pipeline.commands)
+}
+
+// Exec sends the queued commands and empties the queue. The returned error is
+// a connection failure, errors of single commands are in their result.
+func (pipeline *Pipeline) Exec(ctx context.Context) ([]PipelineResult, error) {
+	commands := pipeline.commands
+	pipeline.commands = [][]string{}
+
+	if len(commands) == 0 {
+		return []PipelineResult{}, nil
+	}
+
+	replies, err := pipeline.client.execute(ctx, commands)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(replies, func(reply resp.Value, _ int) PipelineResult {
+		return PipelineResult{Reply: reply, Err: replyError(reply)}
+	}), nil
+}
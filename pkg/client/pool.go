@@ -0,0 +1,262 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"redigo/pkg/resp"
+
+	"github.com/samber/lo"
+)
+
+type connection struct {
+	netConnection net.Conn
+	reader        *resp.Reader
+	writer        *resp.Writer
+	lastUsed      time.Time
+}
+
+// roundTrip sends every command in a single write and reads one reply per
+// command. The deadline comes from ctx, or from timeout when ctx has none, and
+// cancelling ctx interrupts the pending I/O. Failures once the write completed
+// are sentErrors.
+func (connection *connection) roundTrip(ctx context.Context, commands [][]string, timeout time.Duration) ([]resp.Value, error) {
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline {
+		deadline = time.Now().Add(timeout)
+	}
+
+	if err := connection.netConnection.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	stopInterrupt := context.AfterFunc(ctx, func() {
+		connection.netConnection.SetDeadline(time.Now())
+	})
+	defer stopInterrupt()
+
+	for _, arguments := range commands {
+		if err := connection.writer.WriteCommand(arguments); err != nil {
+			return nil, contextError(ctx, err)
+		}
+	}
+	if err := connection.writer.Flush(); err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	replies := make([]resp.Value, 0, len(commands))
+	for range commands {
+		reply, err := connection.reader.ReadValue()
+		if err != nil {
+			return nil, sentError{contextError(ctx, err)}
+		}
+		replies = append(replies, reply)
+	}
+
+	connection.lastUsed = time.Now()
+	return replies, nil
+}
+
+func (connection *connection) close() error {
+	return connection.netConnection.Close()
+}
+
+// sentError is a failure after the commands were written, the server may have
+// run them so they must not be sent again.
+type sentError struct {
+	err error
+}
+
+func (err sentError) Error() string {
+	return err.err.Error()
+}
+
+func (err sentError) Unwrap() error {
+	return err.err
+}
+
+// contextError reports the context error rather than the I/O timeout it caused.
+func contextError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+type pool struct {
+	options     Options
+	slots       chan struct{} // Holds one token per connection in use, bounds the pool size
+	idle        []*connection // Connections ready to be reused, the most recently used last
+	idleMutex   sync.Mutex    // Protects idle and closed
+	closed      bool
+	dialer      net.Dialer
+	healthCheck [][]string
+}
+
+func newPool(options Options) *pool {
+	return &pool{
+		options:     options,
+		slots:       make(chan struct{}, options.PoolSize),
+		idle:        []*connection{},
+		dialer:      net.Dialer{Timeout: options.DialTimeout},
+		healthCheck: [][]string{{"PING"}},
+	}
+}
+
+// get waits for a free slot, then reuses an idle connection or dials a new one.
+// Connections idle for longer than the health check interval are pinged first
+// and dropped if the server does not answer.
+func (pool *pool) get(ctx context.Context) (*connection, error) {
+	select {
+	case pool.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	for {
+		connection, err := pool.popIdle()
+		if err != nil {
+			<-pool.slots
+			return nil, err
+		}
+
+		if connection == nil {
+			break
+		}
+
+		if time.Since(connection.lastUsed) < pool.options.HealthCheckInterval {
+			return connection, nil
+		}
+
+		if _, err := connection.roundTrip(ctx, pool.healthCheck, pool.options.DialTimeout); err == nil {
+			return connection, nil
+		}
+		connection.close()
+	}
+
+	connection, err := pool.dial(ctx)
+	if err != nil {
+		<-pool.slots
+		return nil, err
+	}
+	return connection, nil
+}
+
+// put gives the connection back to the pool, broken connections are closed.
+func (pool *pool) put(connection *connection, broken bool) {
+	defer func() { <-pool.slots }()
+
+	pool.idleMutex.Lock()
+	defer pool.idleMutex.Unlock()
+
+	if broken || pool.closed {
+		connection.close()
+		return
+	}
+	pool.idle = append(pool.idle, connection)
+}
+
+func (pool *pool) popIdle() (*connection, error) {
+	pool.idleMutex.Lock()
+	defer pool.idleMutex.Unlock()
+
+	if pool.closed {
+		return nil, ErrorClientClosed
+	}
+
+	if len(pool.idle) == 0 {
+		return nil, nil
+	}
+
+	connection := pool.idle[len(pool.idle)-1]
+	pool.idle = pool.idle[:len(pool.idle)-1]
+	return connection, nil
+}
+
+func (pool *pool) dial(ctx context.Context) (*connection, error) {
+	return pool.dialAs(ctx, pool.options)
+}
+
+// dialAs dials a connection authenticated and on the database of options,
+// which may differ from the ones of the pool.
+func (pool *pool) dialAs(ctx context.Context, options Options) (*connection, error) {
+	netConnection, err := pool.dialer.DialContext(ctx, options.Network, options.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.TLSConfig != nil {
+		tlsConnection := tls.Client(netConnection, options.TLSConfig)
+		if err := tlsConnection.HandshakeContext(ctx); err != nil {
+			netConnection.Close()
+			return nil, err
+		}
+		netConnection = tlsConnection
+	}
+
+	connection := &connection{
+		netConnection: netConnection,
+		reader:        resp.NewReader(netConnection),
+		writer:        resp.NewWriter(netConnection),
+		lastUsed:      time.Now(),
+	}
+
+	handshake := [][]string{}
+	if options.Password != "" {
+		handshake = append(handshake, lo.Ternary(
+			options.Username != "",
+			[]string{"AUTH", options.Username, options.Password},
+			[]string{"AUTH", options.Password},
+		))
+	}
+	// Misses are answered with their error so that EXPIRED is told apart from NOTFOUND.
+	handshake = append(handshake, []string{"CLIENT", "NILERRORS", "ON"})
+	if options.Database != 0 {
+		handshake = append(handshake, []string{"SELECT", strconv.Itoa(options.Database)})
+	}
+
+	for _, arguments := range handshake {
+		if err := connection.setUp(ctx, arguments, options.DialTimeout); err != nil {
+			connection.close()
+			return nil, err
+		}
+	}
+
+	return connection, nil
+}
+
+// setUp runs a command preparing a new connection. Nothing was sent on the
+// connection before, so its failures are dial failures and can be retried.
+func (connection *connection) setUp(ctx context.Context, arguments []string, timeout time.Duration) error {
+	replies, err := connection.roundTrip(ctx, [][]string{arguments}, timeout)
+
+	var failure sentError
+	if errors.As(err, &failure) {
+		return failure.err
+	} else if err != nil {
+		return err
+	}
+	return replyError(replies[0])
+}
+
+// close closes the idle connections, connections in use are closed when given back.
+func (pool *pool) close() error {
+	pool.idleMutex.Lock()
+	defer pool.idleMutex.Unlock()
+
+	if pool.closed {
+		return ErrorClientClosed
+	}
+
+	pool.closed = true
+	for _, connection := range pool.idle {
+		connection.close()
+	}
+	pool.idle = nil
+	return nil
+}
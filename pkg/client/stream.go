@@ -0,0 +1,62 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"redigo/pkg/resp"
+)
+
+// Stream reads the replies pushed on a connection switched to push mode by
+// SUBSCRIBE, PSUBSCRIBE or MONITOR. It is not safe for concurrent use.
+type Stream struct {
+	connection *connection
+}
+
+// Stream sends a command on a connection of its own, outside of the pool and
+// not counted in PoolSize, and returns the stream of the replies that follow.
+func (client *Client) Stream(ctx context.Context, arguments ...string) (*Stream, error) {
+	connection, err := client.pool.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline {
+		deadline = time.Now().Add(client.options.Timeout)
+	}
+	connection.netConnection.SetWriteDeadline(deadline)
+
+	if err := connection.writer.WriteCommand(arguments); err != nil {
+		connection.close()
+		return nil, err
+	}
+	if err := connection.writer.Flush(); err != nil {
+		connection.close()
+		return nil, err
+	}
+
+	return &Stream{connection: connection}, nil
+}
+
+// Receive waits for the next reply or pushed message, without timeout until
+// ctx is done. Error replies are returned as errors along with the reply.
+func (stream *Stream) Receive(ctx context.Context) (resp.Value, error) {
+	netConnection := stream.connection.netConnection
+	netConnection.SetReadDeadline(time.Time{})
+
+	stopInterrupt := context.AfterFunc(ctx, func() {
+		netConnection.SetReadDeadline(time.Now())
+	})
+	defer stopInterrupt()
+
+	reply, err := stream.connection.reader.ReadValue()
+	if err != nil {
+		return resp.Value{}, contextError(ctx, err)
+	}
+	return reply, replyError(reply)
+}
+
+func (stream *Stream) Close() error {
+	return stream.connection.close()
+}
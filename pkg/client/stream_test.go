@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"redigo/pkg/resp"
+)
+
+func TestStream(t *testing.T) {
+	server := newFakeServer(t, func(_ int, arguments []string) (resp.Value, bool) {
+		if arguments[0] == "SUBSCRIBE" {
+			return resp.NewArray([]resp.Value{resp.NewBulkString("subscribe"), resp.NewBulkString(arguments[1]), resp.NewInteger(1)}), true
+		}
+		return resp.NewSimpleString("OK"), true
+	})
+	client := newTestClient(server, Options{PoolSize: 1})
+	defer client.Close()
+
+	stream, err := client.Stream(context.Background(), "SUBSCRIBE", "news")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	reply, err := stream.Receive(context.Background())
+	if err != nil || len(reply.Array) != 3 || reply.Array[1].Str != "news" {
+		t.Fatalf("Receive() = %+v, %v", reply, err)
+	}
+
+	// The stream connection is not taken from the pool.
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("PING with a stream open: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := stream.Receive(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Receive() without messages returned %v, expected the context error", err)
+	}
+}
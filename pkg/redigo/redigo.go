@@ -0,0 +1,127 @@
+// Package redigo embeds a Redigo database in a Go program, without the network
+// server. It is configured with options only, environment variables are never read.
+//
+//	database, err := redigo.Open(redigo.WithDataDir("/var/lib/myservice"))
+//	if err != nil {
+//		return err
+//	}
+//	defer database.Close()
+package redigo
+
+import (
+	"time"
+
+	internalRedigo "redigo/internal/redigo"
+	redigoErrors "redigo/internal/redigo/errors"
+	"redigo/pkg/utils"
+)
+
+// NO_EXPIRATION is the TTL of keys that never expire.
+const NO_EXPIRATION time.Duration = -1
+
+var ErrorKeyNotFound = redigoErrors.ErrorKeyNotFound
+var ErrorKeyExpired = redigoErrors.ErrorKeyExpired
+var ErrorKeyAlreadyExists = redigoErrors.ErrorKeyAlreadyExists
+var ErrorUnsupportedValueType = redigoErrors.ErrorUnsupportedValueType
+var ErrorPersistenceDisabled = redigoErrors.ErrorPersistenceDisabled
+
+type Option = internalRedigo.Option
+
+// WithDataDir sets the directory of the snapshot, AOF and index files, ~/.redigo by default.
+var WithDataDir = internalRedigo.WithDataDir
+
+// WithSnapshotInterval sets the interval between two automatic snapshots, 5 minutes by default.
+var WithSnapshotInterval = internalRedigo.WithSnapshotInterval
+
+// WithFlushBufferInterval sets the interval between two AOF buffer flushes, 10 minutes by default.
+var WithFlushBufferInterval = internalRedigo.WithFlushBufferInterval
+
+// WithExpirationInterval sets the interval between two sweeps of the expired keys, 1 minute by default.
+var WithExpirationInterval = internalRedigo.WithExpirationInterval
+
+// WithDefaultTTL sets the TTL of keys set with Set, no expiration by default.
+var WithDefaultTTL = internalRedigo.WithDefaultTTL
+
+// WithInMemory disables persistence, nothing is read from or written to disk.
+var WithInMemory = internalRedigo.WithInMemory
+
+type DB struct {
+	database *internalRedigo.Database // Logical database 0, the only one used when embedded
+}
+
+// Open loads the database and starts its background listeners, which run until Close.
+func Open(options ...Option) (*DB, error) {
+	database, err := internalRedigo.Open(options...)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{database: database.Database(0)}, nil
+}
+
+// Close stops the background listeners and flushes the AOF buffer to disk.
+// Only the first call has an effect.
+func (db *DB) Close() error {
+	return db.database.Shutdown(false)
+}
+
+// Set stores a new key with the default TTL. Values are strings, ints, bools
+// or float64s, existing keys are not overwritten and return ErrorKeyAlreadyExists.
+func (db *DB) Set(key string, value any) error {
+	return db.database.Set(key, value, db.database.DefaultTtl())
+}
+
+// SetWithTTL stores a new key that expires after ttl, rounded up to the second, or never when ttl is 0.
+func (db *DB) SetWithTTL(key string, value any, ttl time.Duration) error {
+	return db.database.Set(key, value, utils.DurationToSeconds(ttl))
+}
+
+// Get returns ErrorKeyNotFound when the key does not exist and ErrorKeyExpired when its TTL elapsed.
+func (db *DB) Get(key string) (any, error) {
+	return db.database.Get(key)
+}
+
+// Delete reports whether the key existed.
+func (db *DB) Delete(key string) bool {
+	return db.database.Delete(key)
+}
+
+// Expire reports whether the key existed and now expires after ttl, rounded up
+// to the second, or never when ttl is 0.
+func (db *DB) Expire(key string, ttl time.Duration) bool {
+	return db.database.SetExpiry(key, utils.DurationToSeconds(ttl))
+}
+
+// TTL returns the remaining time to live of the key, NO_EXPIRATION when it
+// never expires and ErrorKeyNotFound when it does not exist.
+func (db *DB) TTL(key string) (time.Duration, error) {
+	seconds, exists := db.database.GetTtl(key)
+	if !exists {
+		return 0, ErrorKeyNotFound
+	}
+
+	if seconds == 0 {
+		return NO_EXPIRATION, nil
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+func (db *DB) SearchByValue(value string) []string {
+	return db.database.SearchByValue(value)
+}
+
+func (db *DB) SearchByPrefix(prefix string) []string {
+	return db.database.SearchByKeyPrefix(prefix)
+}
+
+func (db *DB) SearchBySuffix(suffix string) []string {
+	return db.database.SearchByKeySuffix(suffix)
+}
+
+func (db *DB) SearchByContains(substring string) []string {
+	return db.database.SearchByKeyContains(substring)
+}
+
+// Save takes a snapshot and truncates the AOF, it returns ErrorPersistenceDisabled in memory.
+func (db *DB) Save() error {
+	return db.database.ForceSave()
+}
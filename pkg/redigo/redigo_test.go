@@ -0,0 +1,33 @@
+package redigo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubSecondTtlsAreRoundedUp(t *testing.T) {
+	database, err := Open(WithInMemory(), WithDefaultTTL(500*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	if err := database.SetWithTTL("ttl", "value", 500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Set("default", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.SetWithTTL("expire", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+	if !database.Expire("expire", time.Millisecond) {
+		t.Fatal("Expire did not find the key")
+	}
+
+	for _, key := range []string{"ttl", "default", "expire"} {
+		if ttl, err := database.TTL(key); err != nil || ttl != time.Second {
+			t.Errorf("TTL(%q) = %v, %v, expected 1s", key, ttl, err)
+		}
+	}
+}
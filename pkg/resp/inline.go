@@ -0,0 +1,110 @@
+package resp
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrorInvalidQuoting = errors.New("protocol.invalidQuoting")
+
+var inlineEscapes = map[byte]byte{
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'b':  '\b',
+	'a':  '\a',
+	'"':  '"',
+	'\\': '\\',
+}
+
+// SplitArguments splits an inline command line into arguments using shell-like
+// rules: double quotes support \n, \r, \t, \b, \a, \\, \" and \xHH escapes,
+// single quotes only support \', and a closing quote must end the argument.
+func SplitArguments(line string) ([]string, error) {
+	arguments := []string{}
+	position := 0
+
+	for {
+		for position < len(line) && isInlineSpace(line[position]) {
+			position++
+		}
+
+		if position >= len(line) {
+			return arguments, nil
+		}
+
+		var current strings.Builder
+		inDoubleQuotes, inSingleQuotes, done := false, false, false
+
+		for !done {
+			if position >= len(line) {
+				if inDoubleQuotes || inSingleQuotes {
+					return nil, ErrorInvalidQuoting
+				}
+				break
+			}
+
+			character := line[position]
+
+			switch {
+			case inDoubleQuotes:
+				if character == '\\' && position+3 < len(line) && line[position+1] == 'x' {
+					if decoded, err := strconv.ParseUint(line[position+2:position+4], 16, 8); err == nil {
+						current.WriteByte(byte(decoded))
+						position += 3
+						break
+					}
+					current.WriteByte(character)
+				} else if character == '\\' && position+1 < len(line) {
+					position++
+					if escaped, ok := inlineEscapes[line[position]]; ok {
+						current.WriteByte(escaped)
+					} else {
+						current.WriteByte(line[position])
+					}
+				} else if character == '"' {
+					if position+1 < len(line) && !isInlineSpace(line[position+1]) {
+						return nil, ErrorInvalidQuoting
+					}
+					done = true
+				} else {
+					current.WriteByte(character)
+				}
+			case inSingleQuotes:
+				if character == '\\' && position+1 < len(line) && line[position+1] == '\'' {
+					position++
+					current.WriteByte('\'')
+				} else if character == '\'' {
+					if position+1 < len(line) && !isInlineSpace(line[position+1]) {
+						return nil, ErrorInvalidQuoting
+					}
+					done = true
+				} else {
+					current.WriteByte(character)
+				}
+			default:
+				switch character {
+				case ' ', '\t', '\n', '\r', '\v', '\f':
+					done = true
+				case '"':
+					inDoubleQuotes = true
+				case '\'':
+					inSingleQuotes = true
+				default:
+					current.WriteByte(character)
+				}
+			}
+
+			if position < len(line) {
+				position++
+			}
+		}
+
+		arguments = append(arguments, current.String())
+	}
+}
+
+func isInlineSpace(character byte) bool {
+	return strings.IndexByte(" \t\n\r\v\f", character) >= 0
+}
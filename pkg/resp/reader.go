@@ -0,0 +1,203 @@
+package resp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+var ErrorProtocol = errors.New("protocol.invalid")
+
+// Bounds applied even when MaxSize is 0, so that a length announced by a frame
+// never decides alone how much memory is allocated.
+const (
+	MAX_BULK_LENGTH      = 512 * 1024 * 1024 // Bytes of a bulk string
+	MAX_AGGREGATE_LENGTH = 1024 * 1024       // Elements of an array or push, pairs of a map
+	BULK_READ_CHUNK_SIZE = 64 * 1024         // Bytes allocated ahead of the data of a bulk string
+)
+
+type Reader struct {
+	reader  *bufio.Reader
+	MaxSize int64 // Maximum size in bytes of a bulk string or inline line, 0 means unlimited
+}
+
+func NewReader(reader io.Reader) *Reader {
+	if bufferedReader, ok := reader.(*bufio.Reader); ok {
+		return &Reader{reader: bufferedReader}
+	}
+	return &Reader{reader: bufio.NewReader(reader)}
+}
+
+// ReadCommand reads the next request, either a RESP array of bulk strings or
+// an inline command line. isInline reports which form the client used so that
+// the reply can be written back in the same form.
+func (reader *Reader) ReadCommand() (arguments []string, isInline bool, err error) {
+	for {
+		firstByte, err := reader.reader.Peek(1)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if Type(firstByte[0]) != ARRAY_TYPE {
+			line, err := reader.readLine()
+			if err != nil {
+				return nil, true, err
+			}
+
+			arguments, err := SplitArguments(line)
+			if err != nil {
+				return nil, true, err
+			}
+			if len(arguments) == 0 {
+				continue
+			}
+			return arguments, true, nil
+		}
+
+		value, err := reader.ReadValue()
+		if err != nil {
+			return nil, false, err
+		}
+
+		arguments = make([]string, 0, len(value.Array))
+		for _, element := range value.Array {
+			if element.Type != BULK_STRING_TYPE && element.Type != SIMPLE_STRING_TYPE {
+				return nil, false, fmt.Errorf("%w: expected bulk string, got '%c'", ErrorProtocol, element.Type)
+			}
+			arguments = append(arguments, element.Str)
+		}
+
+		if len(arguments) == 0 {
+			continue
+		}
+		return arguments, false, nil
+	}
+}
+
+func (reader *Reader) ReadValue() (Value, error) {
+	line, err := reader.readLine()
+	if err != nil {
+		return Value{}, err
+	}
+
+	if len(line) == 0 {
+		return Value{}, fmt.Errorf("%w: empty line", ErrorProtocol)
+	}
+
+	valueType, payload := Type(line[0]), line[1:]
+
+	switch valueType {
+	case SIMPLE_STRING_TYPE, ERROR_TYPE:
+		return Value{Type: valueType, Str: payload}, nil
+	case INTEGER_TYPE:
+		number, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return Value{}, fmt.Errorf("%w: invalid integer '%s'", ErrorProtocol, payload)
+		}
+		return NewInteger(number), nil
+	case NULL_TYPE:
+		return NewNull(), nil
+	case BOOLEAN_TYPE:
+		return NewBoolean(payload == "t"), nil
+	case BULK_STRING_TYPE:
+		return reader.readBulkString(payload)
+	case ARRAY_TYPE, PUSH_TYPE, MAP_TYPE:
+		return reader.readAggregate(valueType, payload)
+	default:
+		return Value{}, fmt.Errorf("%w: unknown type '%c'", ErrorProtocol, valueType)
+	}
+}
+
+func (reader *Reader) Buffered() int {
+	return reader.reader.Buffered()
+}
+
+func (reader *Reader) readBulkString(payload string) (Value, error) {
+	length, err := strconv.Atoi(payload)
+	if err != nil {
+		return Value{}, fmt.Errorf("%w: invalid bulk length '%s'", ErrorProtocol, payload)
+	}
+
+	if length < 0 {
+		return NewNull(), nil
+	}
+
+	limit := int64(MAX_BULK_LENGTH)
+	if reader.MaxSize > 0 {
+		limit = min(reader.MaxSize, limit)
+	}
+	if int64(length) > limit {
+		return Value{}, fmt.Errorf("%w: bulk length %d exceeds the %d bytes limit", ErrorProtocol, length, limit)
+	}
+
+	// The buffer grows as the data arrives instead of trusting the length.
+	var data bytes.Buffer
+	data.Grow(min(length+2, BULK_READ_CHUNK_SIZE))
+	if _, err := io.CopyN(&data, reader.reader, int64(length)+2); err != nil {
+		return Value{}, lo.Ternary(errors.Is(err, io.EOF), io.ErrUnexpectedEOF, err)
+	}
+
+	payloadBytes := data.Bytes()
+	if payloadBytes[length] != '\r' || payloadBytes[length+1] != '\n' {
+		return Value{}, fmt.Errorf("%w: bulk string not terminated by CRLF", ErrorProtocol)
+	}
+
+	return NewBulkString(string(payloadBytes[:length])), nil
+}
+
+func (reader *Reader) readAggregate(valueType Type, payload string) (Value, error) {
+	count, err := strconv.Atoi(payload)
+	if err != nil {
+		return Value{}, fmt.Errorf("%w: invalid aggregate length '%s'", ErrorProtocol, payload)
+	}
+
+	if count < 0 {
+		return NewNull(), nil
+	}
+
+	if count > MAX_AGGREGATE_LENGTH {
+		return Value{}, fmt.Errorf("%w: aggregate length %d exceeds the %d elements limit", ErrorProtocol, count, MAX_AGGREGATE_LENGTH)
+	}
+
+	if valueType == MAP_TYPE {
+		count *= 2
+	}
+
+	elements := make([]Value, 0, min(count, 1024))
+	for range count {
+		element, err := reader.ReadValue()
+		if err != nil {
+			return Value{}, err
+		}
+		elements = append(elements, element)
+	}
+
+	return Value{Type: valueType, Array: elements}, nil
+}
+
+func (reader *Reader) readLine() (string, error) {
+	var line []byte
+
+	for {
+		chunk, err := reader.reader.ReadSlice('\n')
+		line = append(line, chunk...)
+
+		if reader.MaxSize > 0 && int64(len(line)) > reader.MaxSize {
+			return "", fmt.Errorf("%w: line exceeds the %d bytes limit", ErrorProtocol, reader.MaxSize)
+		}
+
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		return strings.TrimRight(string(line), "\r\n"), nil
+	}
+}
@@ -0,0 +1,159 @@
+package resp
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValueRoundTrip(t *testing.T) {
+	values := []Value{
+		NewSimpleString("OK"),
+		NewError("NOTFOUND key 'a' not found"),
+		NewInteger(-42),
+		NewBulkString("line\r\nwith CRLF"),
+		NewBulkString(""),
+		NewArray([]Value{NewBulkString("a"), NewInteger(1), NewArray([]Value{})}),
+		NewNull(),
+		NewBoolean(true),
+		NewMap(NewBulkString("key"), NewBulkString("value")),
+		NewPush([]Value{NewBulkString("message"), NewBulkString("channel"), NewBulkString("payload")}),
+	}
+
+	for _, value := range values {
+		var buffer bytes.Buffer
+		writer := NewWriter(&buffer)
+		writer.Protocol = RESP3
+
+		if err := writer.WriteValue(value); err != nil {
+			t.Fatalf("WriteValue(%+v): %v", value, err)
+		}
+		if err := writer.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		read, err := NewReader(&buffer).ReadValue()
+		if err != nil {
+			t.Fatalf("ReadValue(%q): %v", buffer.String(), err)
+		}
+		if !reflect.DeepEqual(read, value) {
+			t.Errorf("round trip of %+v returned %+v", value, read)
+		}
+	}
+}
+
+func TestResp2Downgrade(t *testing.T) {
+	tests := []struct {
+		value    Value
+		expected string
+	}{
+		{NewNull(), "$-1\r\n"},
+		{NewBoolean(true), ":1\r\n"},
+		{NewMap(NewBulkString("a"), NewInteger(1)), "*2\r\n$1\r\na\r\n:1\r\n"},
+		{NewPush([]Value{NewBulkString("a")}), "*1\r\n$1\r\na\r\n"},
+	}
+
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		writer := NewWriter(&buffer)
+
+		if err := writer.WriteValue(test.value); err != nil {
+			t.Fatal(err)
+		}
+		writer.Flush()
+
+		if buffer.String() != test.expected {
+			t.Errorf("RESP2 serialization of %+v is %q, expected %q", test.value, buffer.String(), test.expected)
+		}
+	}
+}
+
+func TestReadCommand(t *testing.T) {
+	tests := []struct {
+		input     string
+		arguments []string
+		isInline  bool
+	}{
+		{"*3\r\n$3\r\nSET\r\n$1\r\na\r\n$5\r\nhello\r\n", []string{"SET", "a", "hello"}, false},
+		{"SET a hello\r\n", []string{"SET", "a", "hello"}, true},
+		{"SET a \"hello world\"\n", []string{"SET", "a", "hello world"}, true},
+		{"SET a 'it\\'s'\r\n", []string{"SET", "a", "it's"}, true},
+		{"SET a \"\\x41\\n\"\r\n", []string{"SET", "a", "A\n"}, true},
+		{"\r\n\r\nPING\r\n", []string{"PING"}, true},
+	}
+
+	for _, test := range tests {
+		arguments, isInline, err := NewReader(strings.NewReader(test.input)).ReadCommand()
+		if err != nil {
+			t.Fatalf("ReadCommand(%q): %v", test.input, err)
+		}
+		if !reflect.DeepEqual(arguments, test.arguments) || isInline != test.isInline {
+			t.Errorf("ReadCommand(%q) = %q, %v, expected %q, %v", test.input, arguments, isInline, test.arguments, test.isInline)
+		}
+	}
+}
+
+func TestReadCommandPipeline(t *testing.T) {
+	reader := NewReader(strings.NewReader("*1\r\n$4\r\nPING\r\nGET a\r\n*2\r\n$3\r\nGET\r\n$1\r\nb\r\n"))
+
+	for _, expected := range [][]string{{"PING"}, {"GET", "a"}, {"GET", "b"}} {
+		arguments, _, err := reader.ReadCommand()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(arguments, expected) {
+			t.Errorf("ReadCommand() = %q, expected %q", arguments, expected)
+		}
+	}
+}
+
+func TestReadCommandInvalidQuoting(t *testing.T) {
+	inputs := []string{"SET a \"unterminated\r\n", "SET a \"closed\"glued\r\n", "SET a 'single\r\n"}
+
+	for _, input := range inputs {
+		if _, _, err := NewReader(strings.NewReader(input)).ReadCommand(); !errors.Is(err, ErrorInvalidQuoting) {
+			t.Errorf("ReadCommand(%q) returned %v, expected ErrorInvalidQuoting", input, err)
+		}
+	}
+}
+
+func TestReadLimits(t *testing.T) {
+	tests := []struct {
+		input   string
+		maxSize int64
+	}{
+		{"$9999999999\r\n", 0},
+		{"*999999999\r\n", 0},
+		{"%999999999\r\n", 0},
+		{">999999999\r\n", 0},
+		{"$11\r\nhello world\r\n", 10},
+		{"*1\r\n$11\r\nhello world\r\n", 10},
+	}
+
+	for _, test := range tests {
+		reader := NewReader(strings.NewReader(test.input))
+		reader.MaxSize = test.maxSize
+
+		if _, err := reader.ReadValue(); !errors.Is(err, ErrorProtocol) {
+			t.Errorf("ReadValue(%q) with MaxSize %d returned %v, expected ErrorProtocol", test.input, test.maxSize, err)
+		}
+	}
+}
+
+func TestReadInlineLimit(t *testing.T) {
+	reader := NewReader(strings.NewReader("SET a hello world\r\n"))
+	reader.MaxSize = 10
+
+	if _, _, err := reader.ReadCommand(); !errors.Is(err, ErrorProtocol) {
+		t.Errorf("ReadCommand of an inline line over MaxSize returned %v, expected ErrorProtocol", err)
+	}
+}
+
+func TestReadTruncatedBulkString(t *testing.T) {
+	_, err := NewReader(strings.NewReader("$10\r\nshort")).ReadValue()
+	if err == nil {
+		t.Fatal("ReadValue of a truncated bulk string succeeded")
+	}
+}
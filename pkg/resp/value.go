@@ -0,0 +1,86 @@
+package resp
+
+import (
+	"github.com/samber/lo"
+)
+
+type Type byte
+
+const (
+	SIMPLE_STRING_TYPE Type = '+'
+	ERROR_TYPE         Type = '-'
+	INTEGER_TYPE       Type = ':'
+	BULK_STRING_TYPE   Type = '$'
+	ARRAY_TYPE         Type = '*'
+	NULL_TYPE          Type = '_' // RESP3 null, serialized as a null bulk string in RESP2
+	BOOLEAN_TYPE       Type = '#' // RESP3 only
+	MAP_TYPE           Type = '%' // RESP3 only, serialized as a flat array in RESP2
+	PUSH_TYPE          Type = '>' // RESP3 only, serialized as an array in RESP2
+)
+
+const (
+	RESP2 = 2
+	RESP3 = 3
+)
+
+type Value struct {
+	Type  Type
+	Str   string  // Payload of simple strings, errors and bulk strings
+	Int   int64   // Payload of integers and booleans (0 or 1)
+	Array []Value // Elements of arrays and pushes, alternating keys and values for maps
+}
+
+func NewSimpleString(value string) Value {
+	return Value{Type: SIMPLE_STRING_TYPE, Str: value}
+}
+
+func NewError(message string) Value {
+	return Value{Type: ERROR_TYPE, Str: message}
+}
+
+func NewInteger(value int64) Value {
+	return Value{Type: INTEGER_TYPE, Int: value}
+}
+
+func NewBulkString(value string) Value {
+	return Value{Type: BULK_STRING_TYPE, Str: value}
+}
+
+func NewNull() Value {
+	return Value{Type: NULL_TYPE}
+}
+
+func NewBoolean(value bool) Value {
+	return Value{Type: BOOLEAN_TYPE, Int: lo.Ternary[int64](value, 1, 0)}
+}
+
+func NewArray(values []Value) Value {
+	return Value{Type: ARRAY_TYPE, Array: lo.Ternary(values == nil, []Value{}, values)}
+}
+
+func NewMap(pairs ...Value) Value {
+	return Value{Type: MAP_TYPE, Array: pairs}
+}
+
+func NewPush(values []Value) Value {
+	return Value{Type: PUSH_TYPE, Array: values}
+}
+
+func NewBulkStringArray(values []string) Value {
+	return NewArray(
+		lo.Map(
+			values,
+			func(value string, _ int) Value {
+				return NewBulkString(value)
+			},
+		),
+	)
+}
+
+func (value Value) IsNull() bool {
+	return value.Type == NULL_TYPE
+}
+
+func (value Value) IsError() bool {
+	return value.Type == ERROR_TYPE
+}
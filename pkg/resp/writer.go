@@ -0,0 +1,113 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var lineBreakReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+type Writer struct {
+	writer   *bufio.Writer
+	Protocol int // RESP2 or RESP3, decides how RESP3-only types are downgraded
+}
+
+func NewWriter(writer io.Writer) *Writer {
+	return &Writer{
+		writer:   bufio.NewWriter(writer),
+		Protocol: RESP2,
+	}
+}
+
+func (writer *Writer) WriteValue(value Value) error {
+	switch value.Type {
+	case SIMPLE_STRING_TYPE, ERROR_TYPE:
+		return writer.writeLine(value.Type, lineBreakReplacer.Replace(value.Str))
+	case INTEGER_TYPE:
+		return writer.writeLine(INTEGER_TYPE, strconv.FormatInt(value.Int, 10))
+	case BULK_STRING_TYPE:
+		return writer.writeBulkString(value.Str)
+	case NULL_TYPE:
+		if writer.Protocol >= RESP3 {
+			return writer.writeLine(NULL_TYPE, "")
+		}
+		return writer.writeLine(BULK_STRING_TYPE, "-1")
+	case BOOLEAN_TYPE:
+		if writer.Protocol >= RESP3 {
+			return writer.writeLine(BOOLEAN_TYPE, map[bool]string{true: "t", false: "f"}[value.Int != 0])
+		}
+		return writer.writeLine(INTEGER_TYPE, strconv.FormatInt(value.Int, 10))
+	case ARRAY_TYPE:
+		return writer.writeAggregate(ARRAY_TYPE, len(value.Array), value.Array)
+	case MAP_TYPE:
+		if writer.Protocol >= RESP3 {
+			return writer.writeAggregate(MAP_TYPE, len(value.Array)/2, value.Array)
+		}
+		return writer.writeAggregate(ARRAY_TYPE, len(value.Array), value.Array)
+	case PUSH_TYPE:
+		if writer.Protocol >= RESP3 {
+			return writer.writeAggregate(PUSH_TYPE, len(value.Array), value.Array)
+		}
+		return writer.writeAggregate(ARRAY_TYPE, len(value.Array), value.Array)
+	default:
+		return fmt.Errorf("%w: cannot serialize type '%c'", ErrorProtocol, value.Type)
+	}
+}
+
+// WriteCommand serializes a request the way clients send it: an array of bulk strings.
+func (writer *Writer) WriteCommand(arguments []string) error {
+	return writer.WriteValue(NewBulkStringArray(arguments))
+}
+
+// WriteInline writes raw text, used to answer clients speaking the inline protocol.
+func (writer *Writer) WriteInline(text string) error {
+	_, err := writer.writer.WriteString(text)
+	return err
+}
+
+func (writer *Writer) Flush() error {
+	return writer.writer.Flush()
+}
+
+func (writer *Writer) Buffered() int {
+	return writer.writer.Buffered()
+}
+
+func (writer *Writer) writeLine(valueType Type, payload string) error {
+	if err := writer.writer.WriteByte(byte(valueType)); err != nil {
+		return err
+	}
+	if _, err := writer.writer.WriteString(payload); err != nil {
+		return err
+	}
+	_, err := writer.writer.WriteString("\r\n")
+	return err
+}
+
+func (writer *Writer) writeBulkString(payload string) error {
+	if err := writer.writeLine(BULK_STRING_TYPE, strconv.Itoa(len(payload))); err != nil {
+		return err
+	}
+	if _, err := writer.writer.WriteString(payload); err != nil {
+		return err
+	}
+	_, err := writer.writer.WriteString("\r\n")
+	return err
+}
+
+func (writer *Writer) writeAggregate(valueType Type, count int, elements []Value) error {
+	if err := writer.writeLine(valueType, strconv.Itoa(count)); err != nil {
+		return err
+	}
+
+	for _, element := range elements {
+		if err := writer.WriteValue(element); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
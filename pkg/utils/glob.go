@@ -0,0 +1,87 @@
+package utils
+
+// MatchGlob reports whether value matches a Redis style glob pattern:
+// * matches any sequence, ? any single character, [abc], [a-z] and [^a]
+// match character classes and \ escapes the next character.
+func MatchGlob(pattern, value string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for index := 0; index <= len(value); index++ {
+				if MatchGlob(pattern[1:], value[index:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(value) == 0 {
+				return false
+			}
+			value = value[1:]
+			pattern = pattern[1:]
+		case '[':
+			if len(value) == 0 {
+				return false
+			}
+			matched, rest, ok := matchGlobClass(pattern[1:], value[0])
+			if !ok {
+				return pattern == value
+			}
+			if !matched {
+				return false
+			}
+			value = value[1:]
+			pattern = rest
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(value) == 0 || pattern[0] != value[0] {
+				return false
+			}
+			value = value[1:]
+			pattern = pattern[1:]
+		}
+	}
+
+	return len(value) == 0
+}
+
+// matchGlobClass matches character against the class starting right after '['
+// and returns the pattern remaining after the closing ']'. ok is false when the
+// class is never closed.
+func matchGlobClass(pattern string, character byte) (matched bool, rest string, ok bool) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+
+	for index := 0; index < len(pattern); index++ {
+		switch {
+		case pattern[index] == ']':
+			return matched != negate, pattern[index+1:], true
+		case pattern[index] == '\\' && index+1 < len(pattern):
+			index++
+			matched = matched || pattern[index] == character
+		case index+2 < len(pattern) && pattern[index+1] == '-' && pattern[index+2] != ']':
+			low, high := pattern[index], pattern[index+2]
+			if low > high {
+				low, high = high, low
+			}
+			matched = matched || (character >= low && character <= high)
+			index += 2
+		default:
+			matched = matched || pattern[index] == character
+		}
+	}
+
+	return false, "", false
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"strconv"
+	"time"
 )
 
 func FromStringToInt64(value string) (int64, error) {
@@ -30,3 +31,12 @@ func ValueToString(value any) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// DurationToSeconds rounds duration up to the second, so that a TTL shorter
+// than a second still expires instead of becoming 0, which means no expiration.
+func DurationToSeconds(duration time.Duration) int64 {
+	if duration > 0 {
+		duration += time.Second - 1
+	}
+	return int64(duration / time.Second)
+}
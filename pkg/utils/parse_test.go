@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationToSeconds(t *testing.T) {
+	tests := map[time.Duration]int64{
+		0:                        0,
+		time.Nanosecond:          1,
+		500 * time.Millisecond:   1,
+		time.Second:              1,
+		1500 * time.Millisecond:  2,
+		-1500 * time.Millisecond: -1,
+	}
+
+	for duration, expected := range tests {
+		if seconds := DurationToSeconds(duration); seconds != expected {
+			t.Errorf("DurationToSeconds(%v) = %d, expected %d", duration, seconds, expected)
+		}
+	}
+}
@@ -4,21 +4,31 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"redigo/envs"
+	"strings"
 )
 
-var envsConfig envs.Envs
-
 const (
 	AOF_FILENAME         = "appendonly.aof"
 	SNAPSHOT_FILENAME    = "snapshot.redigo.json"
 	INDEXES_FILENAME     = "indexes.redigo.json"
+	ACL_FILENAME         = "acl.redigo.json"
 	REDIGO_ROOT_DIR_NAME = ".redigo"
 )
 
-func GetRedigoFullPath() (string, error) {
-	rootDirPath := envsConfig.RedigoRootDirPath
+// DatabaseFilename returns the name of the file of the logical database
+// numbered index, filename itself for the database 0.
+func DatabaseFilename(filename string, index int) string {
+	if index == 0 {
+		return filename
+	}
 
+	extension := filepath.Ext(filename)
+	return fmt.Sprintf("%s.db%d%s", strings.TrimSuffix(filename, extension), index, extension)
+}
+
+// GetRedigoFullPath returns the .redigo directory inside rootDirPath, or
+// inside the user home directory when rootDirPath is empty, creating it if needed.
+func GetRedigoFullPath(rootDirPath string) (string, error) {
 	if rootDirPath == "" {
 		userHomeDir, err := os.UserHomeDir()
 		if err != nil {
@@ -35,30 +45,3 @@ func GetRedigoFullPath() (string, error) {
 
 	return redigoFullDirPath, nil
 }
-
-func GetSnapshotFilePath() (string, error) {
-	redigoDirFullPath, err := GetRedigoFullPath()
-	if err != nil {
-		return "", err
-	}
-
-	return filepath.Join(redigoDirFullPath, SNAPSHOT_FILENAME), nil
-}
-
-func GetIndexesFilePath() (string, error) {
-	redigoDirFullPath, err := GetRedigoFullPath()
-	if err != nil {
-		return "", err
-	}
-
-	return filepath.Join(redigoDirFullPath, INDEXES_FILENAME), nil
-}
-
-func GetAOFPath() (string, error) {
-	redigoDirFullPath, err := GetRedigoFullPath()
-	if err != nil {
-		return "", err
-	}
-
-	return filepath.Join(redigoDirFullPath, AOF_FILENAME), nil
-}
\ No newline at end of file